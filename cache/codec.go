@@ -0,0 +1,65 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+
+	"github.com/ugorji/go/codec"
+)
+
+// Codec 缓存值的序列化方式
+type Codec interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// 内置的序列化方式
+var (
+	JSONCodec    Codec = jsonCodec{}
+	MsgpackCodec Codec = msgpackCodec{}
+	GobCodec     Codec = gobCodec{}
+)
+
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+// msgpackHandle 使用新版规范编码字符串与二进制，并按 json 标签命名字段
+var msgpackHandle = func() *codec.MsgpackHandle {
+	h := &codec.MsgpackHandle{WriteExt: true}
+	h.TypeInfos = codec.NewTypeInfos([]string{"msgpack", "json"})
+	return h
+}()
+
+type msgpackCodec struct{}
+
+func (msgpackCodec) Marshal(v interface{}) ([]byte, error) {
+	var b []byte
+	err := codec.NewEncoderBytes(&b, msgpackHandle).Encode(v)
+	return b, err
+}
+
+func (msgpackCodec) Unmarshal(data []byte, v interface{}) error {
+	return codec.NewDecoderBytes(data, msgpackHandle).Decode(v)
+}
+
+type gobCodec struct{}
+
+func (gobCodec) Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (gobCodec) Unmarshal(data []byte, v interface{}) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
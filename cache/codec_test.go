@@ -0,0 +1,43 @@
+package cache
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type codecUser struct {
+	ID    int64    `json:"id"`
+	Name  string   `json:"name"`
+	Tags  []string `json:"tags"`
+	Admin bool     `json:"admin"`
+}
+
+func TestCodecs(t *testing.T) {
+	in := codecUser{ID: 42, Name: "alice", Tags: []string{"a", "b"}, Admin: true}
+	for name, c := range map[string]Codec{"json": JSONCodec, "msgpack": MsgpackCodec, "gob": GobCodec} {
+		data, err := c.Marshal(in)
+		if err != nil {
+			t.Fatalf("%s marshal: %v", name, err)
+		}
+		var out codecUser
+		if err := c.Unmarshal(data, &out); err != nil {
+			t.Fatalf("%s unmarshal: %v", name, err)
+		}
+		if !reflect.DeepEqual(in, out) {
+			t.Errorf("%s round trip = %+v, want %+v", name, out, in)
+		}
+	}
+}
+
+func TestJitterTTL(t *testing.T) {
+	ttl := time.Minute
+	for i := 0; i < 100; i++ {
+		if d := jitterTTL(ttl, 0.1); d < ttl || d >= ttl+6*time.Second {
+			t.Fatalf("jitterTTL = %v, want in [%v, %v)", d, ttl, ttl+6*time.Second)
+		}
+	}
+	if d := jitterTTL(ttl, 0); d != ttl {
+		t.Errorf("jitterTTL without jitter = %v, want %v", d, ttl)
+	}
+}
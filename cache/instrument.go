@@ -0,0 +1,247 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/shrimps80/go-service-utils/internal/prom"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RedisOption NewRedis 的可选项
+type RedisOption func(*redisOptions)
+
+type redisOptions struct {
+	name           string
+	metricsReg     prometheus.Registerer
+	tracerProvider trace.TracerProvider
+}
+
+// WithInstanceName 设置实例名称，用作指标的 instance 标签与 span 属性，默认 "default"
+func WithInstanceName(name string) RedisOption {
+	return func(o *redisOptions) {
+		o.name = name
+	}
+}
+
+// WithMetrics 启用 Prometheus 指标（命令耗时、错误数、连接池统计）并注册到 reg，reg 为空时使用 prometheus.DefaultRegisterer
+func WithMetrics(reg prometheus.Registerer) RedisOption {
+	return func(o *redisOptions) {
+		if reg == nil {
+			reg = prometheus.DefaultRegisterer
+		}
+		o.metricsReg = reg
+	}
+}
+
+// WithTracing 启用 OpenTelemetry 追踪，每条命令（或每个管道）生成一个 span，
+// 并挂在 ctx 中已有的 span（如 middleware.Tracing 创建的请求 span）之下；tp 为空时使用全局 TracerProvider
+func WithTracing(tp trace.TracerProvider) RedisOption {
+	return func(o *redisOptions) {
+		if tp == nil {
+			tp = otel.GetTracerProvider()
+		}
+		o.tracerProvider = tp
+	}
+}
+
+// redisHook 记录命令指标与追踪的 go-redis 钩子
+type redisHook struct {
+	name     string
+	tracer   trace.Tracer
+	duration *prometheus.HistogramVec
+	errors   *prometheus.CounterVec
+}
+
+type hookStartKey struct{}
+
+// newRedisHook 根据选项创建钩子，未启用指标与追踪时返回 nil；启用指标时同时注册连接池统计并返回，由调用方在关闭时注销
+func newRedisHook(client redis.UniversalClient, o *redisOptions) (*redisHook, *poolStats) {
+	if o.metricsReg == nil && o.tracerProvider == nil {
+		return nil, nil
+	}
+
+	var stats *poolStats
+
+	h := &redisHook{name: o.name}
+	if o.tracerProvider != nil {
+		h.tracer = o.tracerProvider.Tracer("github.com/shrimps80/go-service-utils/cache")
+	}
+	if o.metricsReg != nil {
+		h.duration = prom.Register(o.metricsReg, prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "redis_command_duration_seconds",
+				Help:    "Redis command latency in seconds",
+				Buckets: []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+			},
+			[]string{"instance", "command"},
+		))
+		h.errors = prom.Register(o.metricsReg, prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "redis_command_errors_total",
+				Help: "Total number of failed Redis commands",
+			},
+			[]string{"instance", "command"},
+		))
+		stats = registerPoolStats(client, o)
+	}
+	return h, stats
+}
+
+// BeforeProcess 实现 redis.Hook
+func (h *redisHook) BeforeProcess(ctx context.Context, cmd redis.Cmder) (context.Context, error) {
+	return h.start(ctx, cmd.FullName(), 1), nil
+}
+
+// AfterProcess 实现 redis.Hook
+func (h *redisHook) AfterProcess(ctx context.Context, cmd redis.Cmder) error {
+	h.finish(ctx, cmd.FullName(), cmd.Err())
+	return nil
+}
+
+// BeforeProcessPipeline 实现 redis.Hook
+func (h *redisHook) BeforeProcessPipeline(ctx context.Context, cmds []redis.Cmder) (context.Context, error) {
+	return h.start(ctx, "pipeline", len(cmds)), nil
+}
+
+// AfterProcessPipeline 实现 redis.Hook，管道中任一命令失败即记为失败
+func (h *redisHook) AfterProcessPipeline(ctx context.Context, cmds []redis.Cmder) error {
+	var err error
+	for _, cmd := range cmds {
+		if cmdErr := cmd.Err(); cmdErr != nil && !errors.Is(cmdErr, redis.Nil) {
+			err = cmdErr
+			break
+		}
+	}
+	h.finish(ctx, "pipeline", err)
+	return nil
+}
+
+// start 记录开始时间并创建 span
+func (h *redisHook) start(ctx context.Context, command string, size int) context.Context {
+	ctx = context.WithValue(ctx, hookStartKey{}, time.Now())
+	if h.tracer != nil {
+		ctx, _ = h.tracer.Start(ctx, "redis."+command,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				attribute.String("db.system", "redis"),
+				attribute.String("db.operation", command),
+				attribute.String("db.redis.instance", h.name),
+				attribute.Int("db.redis.num_cmd", size),
+			),
+		)
+	}
+	return ctx
+}
+
+// finish 记录耗时、错误并结束 span；redis.Nil（键不存在）不视为错误
+func (h *redisHook) finish(ctx context.Context, command string, err error) {
+	if errors.Is(err, redis.Nil) {
+		err = nil
+	}
+
+	if start, ok := ctx.Value(hookStartKey{}).(time.Time); ok && h.duration != nil {
+		h.duration.WithLabelValues(h.name, command).Observe(time.Since(start).Seconds())
+	}
+	if err != nil && h.errors != nil {
+		h.errors.WithLabelValues(h.name, command).Inc()
+	}
+
+	if h.tracer != nil {
+		span := trace.SpanFromContext(ctx)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}
+}
+
+// poolStats 引用客户端的连接池统计采集器，客户端关闭时注销
+type poolStats struct {
+	key        poolStatsKey
+	collectors []prometheus.Collector
+}
+
+// poolStatsKey 连接池统计采集器按注册器与实例名称唯一
+type poolStatsKey struct {
+	reg  prometheus.Registerer
+	name string
+}
+
+// poolStatsOwners 记录每组连接池统计采集器当前所属的客户端，
+// 同名实例重新创建后，旧客户端关闭时不会注销新客户端的采集器
+var (
+	poolStatsMu     sync.Mutex
+	poolStatsOwners = make(map[poolStatsKey]*poolStats)
+)
+
+// registerPoolStats 注册连接池统计指标，同名实例重新创建时替换旧的采集器
+func registerPoolStats(client redis.UniversalClient, o *redisOptions) *poolStats {
+	labels := prometheus.Labels{"instance": o.name}
+	gauges := []struct {
+		name string
+		help string
+		fn   func(s *redis.PoolStats) uint32
+	}{
+		{"redis_pool_total_conns", "Number of total connections in the pool", func(s *redis.PoolStats) uint32 { return s.TotalConns }},
+		{"redis_pool_idle_conns", "Number of idle connections in the pool", func(s *redis.PoolStats) uint32 { return s.IdleConns }},
+	}
+	counters := []struct {
+		name string
+		help string
+		fn   func(s *redis.PoolStats) uint32
+	}{
+		{"redis_pool_hits_total", "Number of times a free connection was found in the pool", func(s *redis.PoolStats) uint32 { return s.Hits }},
+		{"redis_pool_misses_total", "Number of times a free connection was not found in the pool", func(s *redis.PoolStats) uint32 { return s.Misses }},
+		{"redis_pool_timeouts_total", "Number of times a wait timeout occurred", func(s *redis.PoolStats) uint32 { return s.Timeouts }},
+		{"redis_pool_stale_conns_total", "Number of stale connections removed from the pool", func(s *redis.PoolStats) uint32 { return s.StaleConns }},
+	}
+
+	stats := &poolStats{key: poolStatsKey{reg: o.metricsReg, name: o.name}}
+	for _, g := range gauges {
+		fn := g.fn
+		stats.collectors = append(stats.collectors, prometheus.NewGaugeFunc(
+			prometheus.GaugeOpts{Name: g.name, Help: g.help, ConstLabels: labels},
+			func() float64 { return float64(fn(client.PoolStats())) },
+		))
+	}
+	for _, c := range counters {
+		fn := c.fn
+		stats.collectors = append(stats.collectors, prometheus.NewCounterFunc(
+			prometheus.CounterOpts{Name: c.name, Help: c.help, ConstLabels: labels},
+			func() float64 { return float64(fn(client.PoolStats())) },
+		))
+	}
+
+	poolStatsMu.Lock()
+	defer poolStatsMu.Unlock()
+	for _, c := range stats.collectors {
+		prom.Replace(o.metricsReg, c)
+	}
+	poolStatsOwners[stats.key] = stats
+	return stats
+}
+
+// unregister 注销连接池统计采集器，使关闭后的客户端不再被注册器引用；采集器已被同名实例替换时跳过
+func (s *poolStats) unregister() {
+	if s == nil {
+		return
+	}
+	poolStatsMu.Lock()
+	defer poolStatsMu.Unlock()
+	if poolStatsOwners[s.key] != s {
+		return
+	}
+	delete(poolStatsOwners, s.key)
+	for _, c := range s.collectors {
+		s.key.reg.Unregister(c)
+	}
+}
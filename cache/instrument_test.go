@@ -0,0 +1,166 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/shrimps80/go-service-utils/internal/tracetest"
+	"go.opentelemetry.io/otel/codes"
+)
+
+func TestRedisInstrumentation(t *testing.T) {
+	mr := miniredis.RunT(t)
+	reg := prometheus.NewRegistry()
+	tp := &tracetest.Provider{}
+	r, err := NewRedis(&RedisConfig{Addrs: []string{mr.Addr()}, PoolSize: 2, Timeout: time.Second},
+		WithInstanceName("main"), WithMetrics(reg), WithTracing(tp))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	ctx, parent := tp.Tracer("test").Start(context.Background(), "request")
+	if err := r.Set(ctx, "k", "v", 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Get(ctx, "missing"); err != redis.Nil {
+		t.Fatalf("Get = %v, want redis.Nil", err)
+	}
+	if _, err := r.Incr(ctx, "k"); err == nil {
+		t.Fatal("expected INCR on a string to fail")
+	}
+	pipe := r.Pipeline()
+	pipe.Get(ctx, "k")
+	pipe.Get(ctx, "missing")
+	if _, err := pipe.Exec(ctx); err != redis.Nil {
+		t.Fatalf("pipeline = %v", err)
+	}
+	parent.End()
+
+	mfs, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	values := map[string]float64{}
+	for _, mf := range mfs {
+		for _, m := range mf.GetMetric() {
+			labels := map[string]string{}
+			for _, lp := range m.GetLabel() {
+				labels[lp.GetName()] = lp.GetValue()
+			}
+			if labels["instance"] != "main" {
+				t.Errorf("%s has instance %q", mf.GetName(), labels["instance"])
+			}
+			switch mf.GetName() {
+			case "redis_command_duration_seconds":
+				values["duration/"+labels["command"]] = float64(m.GetHistogram().GetSampleCount())
+			case "redis_command_errors_total":
+				values["errors/"+labels["command"]] = m.GetCounter().GetValue()
+			default:
+				values[mf.GetName()] = 1
+			}
+		}
+	}
+	for key, want := range map[string]float64{
+		"duration/set":           1,
+		"duration/get":           1,
+		"duration/incr":          1,
+		"duration/pipeline":      1,
+		"errors/incr":            1,
+		"redis_pool_total_conns": 1,
+		"redis_pool_hits_total":  1,
+	} {
+		if got := values[key]; got != want {
+			t.Errorf("%s = %v, want %v", key, got, want)
+		}
+	}
+	for _, key := range []string{"errors/get", "errors/pipeline", "errors/set"} {
+		if _, ok := values[key]; ok {
+			t.Errorf("%s recorded, redis.Nil should not count as an error", key)
+		}
+	}
+
+	statuses := map[string]codes.Code{}
+	for _, s := range tp.Spans() {
+		if !s.Ended {
+			t.Errorf("span %s not ended", s.Name)
+		}
+		if s.Name != "request" && s.Name != "redis.ping" && s.Parent != "request" {
+			t.Errorf("span %s parent = %q, want request", s.Name, s.Parent)
+		}
+		statuses[s.Name] = s.Status
+	}
+	if statuses["redis.incr"] != codes.Error || statuses["redis.get"] == codes.Error || statuses["redis.pipeline"] == codes.Error {
+		t.Errorf("unexpected span statuses: %v", statuses)
+	}
+}
+
+// hasMetric 判断注册器中是否存在指定名称的指标
+func hasMetric(t *testing.T, reg *prometheus.Registry, name string) bool {
+	t.Helper()
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range families {
+		if f.GetName() == name {
+			return true
+		}
+	}
+	return false
+}
+
+func TestRedisPoolStatsUnregister(t *testing.T) {
+	mr := miniredis.RunT(t)
+	reg := prometheus.NewRegistry()
+
+	r, err := NewRedis(&RedisConfig{Addrs: []string{mr.Addr()}, Timeout: time.Second}, WithMetrics(reg))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !hasMetric(t, reg, "redis_pool_total_conns") {
+		t.Fatal("pool stats not registered")
+	}
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if hasMetric(t, reg, "redis_pool_total_conns") {
+		t.Fatal("pool stats still registered after Close")
+	}
+
+	// 连接失败时关闭客户端并注销采集器
+	addr := mr.Addr()
+	mr.Close()
+	if _, err := NewRedis(&RedisConfig{Addrs: []string{addr}, Timeout: 200 * time.Millisecond}, WithMetrics(reg)); err == nil {
+		t.Fatal("expected ping to fail")
+	}
+	if hasMetric(t, reg, "redis_pool_total_conns") {
+		t.Fatal("pool stats registered after failed ping")
+	}
+}
+
+func TestRedisPoolStatsReplaced(t *testing.T) {
+	mr := miniredis.RunT(t)
+	reg := prometheus.NewRegistry()
+	cfg := &RedisConfig{Addrs: []string{mr.Addr()}, Timeout: time.Second}
+
+	old, err := NewRedis(cfg, WithMetrics(reg))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := NewRedis(cfg, WithMetrics(reg))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	// 旧客户端关闭时不注销同名新客户端的采集器
+	_ = old.Close()
+	if !hasMetric(t, reg, "redis_pool_total_conns") {
+		t.Fatal("closing the replaced client unregistered the new pool stats")
+	}
+}
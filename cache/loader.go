@@ -0,0 +1,170 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"time"
+)
+
+// ErrNotFound loader 返回该错误表示数据不存在，GetOrLoad 会在短时间内缓存这一结果以防止缓存穿透
+var ErrNotFound = errors.New("cache: not found")
+
+// ErrTypeMismatch 加载结果的类型与调用方要求的类型不一致
+var ErrTypeMismatch = errors.New("cache: type mismatch")
+
+// negativeValue 不存在的数据在 Redis 中的占位值
+const negativeValue = "\x00cache:not-found\x00"
+
+// LoadOption GetOrLoad 的可选项
+type LoadOption func(*loadOptions)
+
+type loadOptions struct {
+	codec       Codec
+	negativeTTL time.Duration
+	jitter      float64
+	loadTimeout time.Duration
+}
+
+// WithCodec 设置缓存值的序列化方式，默认 JSONCodec
+func WithCodec(c Codec) LoadOption {
+	return func(o *loadOptions) {
+		o.codec = c
+	}
+}
+
+// WithNegativeTTL 设置 ErrNotFound 结果的缓存时间，默认 30 秒，为 0 时不缓存
+func WithNegativeTTL(ttl time.Duration) LoadOption {
+	return func(o *loadOptions) {
+		o.negativeTTL = ttl
+	}
+}
+
+// WithJitter 设置过期时间的随机增量占 ttl 的比例，默认 0.1（即 ttl 到 1.1*ttl 之间），为 0 时不加随机量
+func WithJitter(fraction float64) LoadOption {
+	return func(o *loadOptions) {
+		o.jitter = fraction
+	}
+}
+
+// WithLoadTimeout 设置 loader 的超时时间，默认 10 秒，为 0 时不限制。
+// 超时后等待中的调用方返回 context.DeadlineExceeded，同一 key 的后续调用重新加载
+func WithLoadTimeout(timeout time.Duration) LoadOption {
+	return func(o *loadOptions) {
+		o.loadTimeout = timeout
+	}
+}
+
+// loadResult loader 的返回值
+type loadResult[T any] struct {
+	v   T
+	err error
+}
+
+// GetOrLoad 从缓存读取 key 对应的值，未命中时调用 loader 加载并写入缓存（cache-aside）。
+// 同一进程内对同一 key 的并发加载只执行一次 loader；loader 返回 ErrNotFound 时缓存空结果，
+// 之后的调用在 negativeTTL 内直接返回 ErrNotFound。Redis 不可用时直接调用 loader，不影响业务
+func GetOrLoad[T any](ctx context.Context, r *Redis, key string, ttl time.Duration, loader func(ctx context.Context) (T, error), opts ...LoadOption) (T, error) {
+	o := &loadOptions{codec: JSONCodec, negativeTTL: 30 * time.Second, jitter: 0.1, loadTimeout: 10 * time.Second}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	var zero T
+	if v, found, err := getCached[T](ctx, r, key, o); err != nil {
+		return zero, err
+	} else if found {
+		return v, nil
+	}
+
+	// 加载不受单个调用方取消的影响，每个调用方各自等待自己的 ctx；加载超时后释放去重的键，
+	// 不响应 ctx 的 loader 不会阻塞后续调用。去重的键包含类型，同一 key 以不同 T 调用时不会共享结果
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+	ch := r.group.DoChan(typ.String()+"\x00"+key, func() (interface{}, error) {
+		loadCtx := context.WithoutCancel(ctx)
+		if o.loadTimeout > 0 {
+			var cancel context.CancelFunc
+			loadCtx, cancel = context.WithTimeout(loadCtx, o.loadTimeout)
+			defer cancel()
+		}
+
+		done := make(chan loadResult[T], 1)
+		go func() {
+			v, err := loader(loadCtx)
+			done <- loadResult[T]{v: v, err: err}
+		}()
+		var v T
+		var err error
+		select {
+		case res := <-done:
+			v, err = res.v, res.err
+		case <-loadCtx.Done():
+			return nil, loadCtx.Err()
+		}
+
+		switch {
+		case errors.Is(err, ErrNotFound):
+			if o.negativeTTL > 0 {
+				_ = r.client.Set(loadCtx, key, negativeValue, o.negativeTTL).Err()
+			}
+			return nil, err
+		case err != nil:
+			return nil, err
+		}
+
+		data, err := o.codec.Marshal(v)
+		if err != nil {
+			return nil, fmt.Errorf("cache: marshal %s: %w", key, err)
+		}
+		_ = r.client.Set(loadCtx, key, data, jitterTTL(ttl, o.jitter)).Err()
+		return v, nil
+	})
+
+	select {
+	case <-ctx.Done():
+		return zero, ctx.Err()
+	case res := <-ch:
+		if res.Err != nil {
+			return zero, res.Err
+		}
+		if res.Val == nil {
+			// T 为接口类型且 loader 返回 nil
+			return zero, nil
+		}
+		v, ok := res.Val.(T)
+		if !ok {
+			return zero, fmt.Errorf("%w: key %s: want %s, got %T", ErrTypeMismatch, key, typ, res.Val)
+		}
+		return v, nil
+	}
+}
+
+// getCached 读取并解码缓存值；Redis 出错或解码失败时按未命中处理
+func getCached[T any](ctx context.Context, r *Redis, key string, o *loadOptions) (T, bool, error) {
+	var v T
+	data, err := r.client.Get(ctx, key).Bytes()
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return v, false, ctxErr
+		}
+		return v, false, nil
+	}
+	if string(data) == negativeValue {
+		return v, false, ErrNotFound
+	}
+	if err := o.codec.Unmarshal(data, &v); err != nil {
+		var zero T
+		return zero, false, nil
+	}
+	return v, true, nil
+}
+
+// jitterTTL 为过期时间加上随机增量，避免同时写入的键同时过期
+func jitterTTL(ttl time.Duration, fraction float64) time.Duration {
+	if ttl <= 0 || fraction <= 0 {
+		return ttl
+	}
+	return ttl + time.Duration(rand.Float64()*fraction*float64(ttl))
+}
@@ -0,0 +1,172 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type loaderUser struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func TestGetOrLoadDedup(t *testing.T) {
+	r, mr := newTestRedis(t)
+	ctx := context.Background()
+
+	var calls atomic.Int32
+	release := make(chan struct{})
+	loader := func(ctx context.Context) (*loaderUser, error) {
+		calls.Add(1)
+		<-release
+		return &loaderUser{ID: 1, Name: "alice"}, nil
+	}
+
+	var wg sync.WaitGroup
+	results := make([]*loaderUser, 10)
+	errs := make([]error, 10)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], errs[i] = GetOrLoad(ctx, r, "user:1", time.Minute, loader)
+		}(i)
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if n := calls.Load(); n != 1 {
+		t.Fatalf("loader called %d times, want 1", n)
+	}
+	for i := range results {
+		if errs[i] != nil || results[i] == nil || results[i].Name != "alice" {
+			t.Fatalf("result %d = %+v, %v", i, results[i], errs[i])
+		}
+	}
+
+	// 之后的调用直接命中缓存，过期时间带有随机增量
+	if _, err := GetOrLoad(ctx, r, "user:1", time.Minute, loader); err != nil || calls.Load() != 1 {
+		t.Fatalf("expected cache hit, calls = %d, err = %v", calls.Load(), err)
+	}
+	if ttl := mr.TTL("user:1"); ttl < time.Minute || ttl > 66*time.Second {
+		t.Errorf("ttl = %v, want within jitter range", ttl)
+	}
+}
+
+func TestGetOrLoadNegativeCache(t *testing.T) {
+	r, mr := newTestRedis(t)
+	ctx := context.Background()
+
+	var calls int
+	loader := func(ctx context.Context) (loaderUser, error) {
+		calls++
+		return loaderUser{}, ErrNotFound
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := GetOrLoad(ctx, r, "user:404", time.Minute, loader, WithNegativeTTL(10*time.Second)); !errors.Is(err, ErrNotFound) {
+			t.Fatalf("expected ErrNotFound, got %v", err)
+		}
+	}
+	if calls != 1 {
+		t.Fatalf("loader called %d times, want 1 (negative cache hit)", calls)
+	}
+
+	mr.FastForward(11 * time.Second)
+	if _, err := GetOrLoad(ctx, r, "user:404", time.Minute, loader); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	if calls != 2 {
+		t.Fatalf("loader called %d times after negative entry expired, want 2", calls)
+	}
+}
+
+func TestGetOrLoadErrors(t *testing.T) {
+	r, mr := newTestRedis(t)
+	ctx := context.Background()
+
+	boom := errors.New("boom")
+	var calls int
+	failing := func(ctx context.Context) (int, error) {
+		calls++
+		return 0, boom
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := GetOrLoad(ctx, r, "n", time.Minute, failing); !errors.Is(err, boom) {
+			t.Fatalf("expected loader error, got %v", err)
+		}
+	}
+	if calls != 2 || mr.Exists("n") {
+		t.Fatalf("loader errors must not be cached: calls = %d", calls)
+	}
+
+	// T 为接口类型且 loader 返回 nil 时不 panic
+	v, err := GetOrLoad(ctx, r, "iface", time.Minute, func(ctx context.Context) (error, error) { return nil, nil })
+	if err != nil || v != nil {
+		t.Fatalf("interface nil result = %v, %v", v, err)
+	}
+
+	// 同一 key 以不同类型调用：解码失败按未命中处理，重新加载
+	if _, err := GetOrLoad(ctx, r, "mixed", time.Minute, func(ctx context.Context) (int, error) { return 1, nil }); err != nil {
+		t.Fatal(err)
+	}
+	s, err := GetOrLoad(ctx, r, "mixed", time.Minute, func(ctx context.Context) (string, error) { return "one", nil })
+	if err != nil || s != "one" {
+		t.Fatalf("mixed types = %q, %v", s, err)
+	}
+}
+
+// failingCodec 序列化总是失败的编解码器
+type failingCodec struct{ err error }
+
+func (c failingCodec) Marshal(interface{}) ([]byte, error) { return nil, c.err }
+func (c failingCodec) Unmarshal([]byte, interface{}) error { return c.err }
+
+func TestGetOrLoadMarshalError(t *testing.T) {
+	r, mr := newTestRedis(t)
+	boom := errors.New("marshal failed")
+
+	_, err := GetOrLoad(context.Background(), r, "m", time.Minute,
+		func(ctx context.Context) (int, error) { return 1, nil }, WithCodec(failingCodec{err: boom}))
+	if !errors.Is(err, boom) {
+		t.Fatalf("got %v, want the marshal error", err)
+	}
+	if mr.Exists("m") {
+		t.Fatal("unmarshalable value written to redis")
+	}
+}
+
+func TestGetOrLoadTimeout(t *testing.T) {
+	r, _ := newTestRedis(t)
+	ctx := context.Background()
+	release := make(chan struct{})
+	defer close(release)
+
+	// 不响应 ctx 的 loader 超时后，同一 key 的后续调用可以重新加载
+	start := time.Now()
+	_, err := GetOrLoad(ctx, r, "slow", time.Minute, func(ctx context.Context) (int, error) {
+		<-release
+		return 0, nil
+	}, WithLoadTimeout(20*time.Millisecond))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got %v, want DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("GetOrLoad blocked for %v", elapsed)
+	}
+
+	v, err := GetOrLoad(ctx, r, "slow", time.Minute, func(ctx context.Context) (int, error) {
+		if _, ok := ctx.Deadline(); !ok {
+			t.Error("loader ctx has no deadline")
+		}
+		return 7, nil
+	})
+	if err != nil || v != 7 {
+		t.Fatalf("reload = %d, %v", v, err)
+	}
+}
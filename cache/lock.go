@@ -0,0 +1,296 @@
+package cache
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/shrimps80/go-service-utils/internal/backoff"
+)
+
+var (
+	// ErrLockNotAcquired 锁已被其他持有者占用
+	ErrLockNotAcquired = errors.New("cache: lock not acquired")
+	// ErrLockLost 租约已过期或被其他持有者获取，当前持有者不再拥有该锁
+	ErrLockLost = errors.New("cache: lock lost")
+)
+
+// 只有令牌匹配时才释放或续期，避免误删其他持有者的锁
+var (
+	releaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+	extendScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0`)
+)
+
+// defaultLockTTL 默认租约时长
+const defaultLockTTL = 30 * time.Second
+
+// LockOption NewLock 的可选项
+type LockOption func(*lockOptions)
+
+type lockOptions struct {
+	ttl           time.Duration
+	renewInterval time.Duration
+	renewSet      bool
+	minBackoff    time.Duration
+	maxBackoff    time.Duration
+}
+
+// WithLockTTL 设置租约时长，默认 30 秒；小于等于 0 时使用默认值，避免创建永不过期的锁
+func WithLockTTL(ttl time.Duration) LockOption {
+	return func(o *lockOptions) {
+		o.ttl = ttl
+	}
+}
+
+// WithRenewInterval 设置看门狗的续期间隔，默认为租约时长的 1/3，小于等于 0 时不自动续期
+func WithRenewInterval(interval time.Duration) LockOption {
+	return func(o *lockOptions) {
+		o.renewInterval = interval
+		o.renewSet = true
+	}
+}
+
+// WithRetryBackoff 设置 Lock 阻塞获取时的重试间隔范围（指数退避加随机抖动），默认 50ms 到 1s
+func WithRetryBackoff(min, max time.Duration) LockOption {
+	return func(o *lockOptions) {
+		o.minBackoff = min
+		o.maxBackoff = max
+	}
+}
+
+// Lock 基于 SET NX PX 的分布式锁。每次获取生成随机令牌，释放与续期通过 Lua 脚本校验令牌；
+// 持有期间看门狗按间隔自动续期，续期失败且租约到期后关闭 Lost 返回的通道
+type Lock struct {
+	r    *Redis
+	key  string
+	opts lockOptions
+
+	mu    sync.Mutex
+	lease *lease
+}
+
+// lease 一次成功获取对应的租约
+type lease struct {
+	token    string
+	lost     chan struct{}
+	lostOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+func (ls *lease) markLost() {
+	ls.lostOnce.Do(func() { close(ls.lost) })
+}
+
+// NewLock 创建 key 对应的锁，创建时不访问 Redis
+func (r *Redis) NewLock(key string, opts ...LockOption) *Lock {
+	o := lockOptions{
+		ttl:        defaultLockTTL,
+		minBackoff: 50 * time.Millisecond,
+		maxBackoff: time.Second,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.ttl <= 0 {
+		o.ttl = defaultLockTTL
+	}
+	if !o.renewSet {
+		o.renewInterval = o.ttl / 3
+	}
+	return &Lock{r: r, key: key, opts: o}
+}
+
+// Key 返回锁的键
+func (l *Lock) Key() string {
+	return l.key
+}
+
+// TryLock 尝试获取一次锁，已被占用时返回 ErrLockNotAcquired
+func (l *Lock) TryLock(ctx context.Context) error {
+	token, err := newLockToken()
+	if err != nil {
+		return err
+	}
+	ok, err := l.r.client.SetNX(ctx, l.key, token, l.opts.ttl).Result()
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrLockNotAcquired
+	}
+
+	ls := &lease{token: token, lost: make(chan struct{})}
+	if l.opts.renewInterval > 0 {
+		ls.stop, ls.done = make(chan struct{}), make(chan struct{})
+		go l.watchdog(ls)
+	}
+
+	// 上一次租约已丢失但未调用 Unlock 时，停止其看门狗
+	l.mu.Lock()
+	old := l.lease
+	l.lease = ls
+	l.mu.Unlock()
+	if old != nil {
+		l.stopWatchdog(old)
+	}
+	return nil
+}
+
+// Lock 阻塞直到获取锁或 ctx 结束，锁被占用时按指数退避重试
+func (l *Lock) Lock(ctx context.Context) error {
+	for attempt := 0; ; attempt++ {
+		err := l.TryLock(ctx)
+		if !errors.Is(err, ErrLockNotAcquired) {
+			return err
+		}
+
+		timer := time.NewTimer(backoff.Exponential(attempt, l.opts.minBackoff, l.opts.maxBackoff))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// Unlock 停止续期并释放锁，锁已不属于当前持有者时返回 ErrLockLost
+func (l *Lock) Unlock(ctx context.Context) error {
+	l.mu.Lock()
+	ls := l.lease
+	l.lease = nil
+	l.mu.Unlock()
+	if ls == nil {
+		return ErrLockLost
+	}
+
+	l.stopWatchdog(ls)
+	n, err := releaseScript.Run(ctx, l.r.client, []string{l.key}, ls.token).Int64()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		ls.markLost()
+		return ErrLockLost
+	}
+	return nil
+}
+
+// Extend 将租约重置为 ttl，锁已不属于当前持有者时返回 ErrLockLost
+func (l *Lock) Extend(ctx context.Context, ttl time.Duration) error {
+	l.mu.Lock()
+	ls := l.lease
+	l.mu.Unlock()
+	if ls == nil {
+		return ErrLockLost
+	}
+	return l.extend(ctx, ls, ttl)
+}
+
+// Lost 返回当前租约丢失时关闭的通道，未持有锁时返回已关闭的通道
+func (l *Lock) Lost() <-chan struct{} {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.lease == nil {
+		ch := make(chan struct{})
+		close(ch)
+		return ch
+	}
+	return l.lease.lost
+}
+
+func (l *Lock) extend(ctx context.Context, ls *lease, ttl time.Duration) error {
+	n, err := extendScript.Run(ctx, l.r.client, []string{l.key}, ls.token, ttl.Milliseconds()).Int64()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		ls.markLost()
+		return ErrLockLost
+	}
+	return nil
+}
+
+// watchdog 定期续期；令牌不匹配，或 Redis 持续出错直到租约到期时，判定租约丢失
+func (l *Lock) watchdog(ls *lease) {
+	defer close(ls.done)
+
+	ticker := time.NewTicker(l.opts.renewInterval)
+	defer ticker.Stop()
+	deadline := time.Now().Add(l.opts.ttl)
+	for {
+		select {
+		case <-ls.stop:
+			return
+		case <-ticker.C:
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), l.opts.renewInterval)
+		err := l.extend(ctx, ls, l.opts.ttl)
+		cancel()
+		switch {
+		case err == nil:
+			deadline = time.Now().Add(l.opts.ttl)
+		case errors.Is(err, ErrLockLost) || !time.Now().Before(deadline):
+			ls.markLost()
+			return
+		}
+	}
+}
+
+func (l *Lock) stopWatchdog(ls *lease) {
+	if ls.stop == nil {
+		return
+	}
+	close(ls.stop)
+	<-ls.done
+}
+
+// newLockToken 生成随机令牌
+func newLockToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// WithLock 阻塞获取 key 对应的锁后执行 fn，结束后释放锁。租约丢失时 fn 的 ctx 被取消，
+// context.Cause(ctx) 为 ErrLockLost；fn 成功但租约已丢失时返回 ErrLockLost
+func (r *Redis) WithLock(ctx context.Context, key string, fn func(ctx context.Context) error, opts ...LockOption) error {
+	l := r.NewLock(key, opts...)
+	if err := l.Lock(ctx); err != nil {
+		return err
+	}
+
+	fnCtx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	lost := l.Lost()
+	go func() {
+		select {
+		case <-lost:
+			cancel(ErrLockLost)
+		case <-fnCtx.Done():
+		}
+	}()
+
+	err := fn(fnCtx)
+	unlockErr := l.Unlock(context.WithoutCancel(ctx))
+	if err != nil {
+		return err
+	}
+	return unlockErr
+}
@@ -0,0 +1,175 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestLockOptions(t *testing.T) {
+	r := &Redis{}
+	l := r.NewLock("job", WithLockTTL(9*time.Second))
+	if l.opts.renewInterval != 3*time.Second {
+		t.Errorf("renewInterval = %v, want 3s", l.opts.renewInterval)
+	}
+	if l := r.NewLock("job", WithRenewInterval(0)); l.opts.renewInterval != 0 {
+		t.Errorf("renewInterval = %v, want watchdog disabled", l.opts.renewInterval)
+	}
+	// 租约时长小于等于 0 时使用默认值，看门狗保持开启
+	for _, ttl := range []time.Duration{0, -time.Second} {
+		if l := r.NewLock("job", WithLockTTL(ttl)); l.opts.ttl != defaultLockTTL || l.opts.renewInterval != defaultLockTTL/3 {
+			t.Errorf("WithLockTTL(%v): ttl = %v, renewInterval = %v", ttl, l.opts.ttl, l.opts.renewInterval)
+		}
+	}
+	l = r.NewLock("job", WithRetryBackoff(10*time.Millisecond, 40*time.Millisecond))
+	if l.opts.minBackoff != 10*time.Millisecond || l.opts.maxBackoff != 40*time.Millisecond {
+		t.Errorf("backoff = [%v, %v]", l.opts.minBackoff, l.opts.maxBackoff)
+	}
+
+	select {
+	case <-l.Lost():
+	default:
+		t.Error("Lost() of an unheld lock should be closed")
+	}
+}
+
+func TestLockTokenCheck(t *testing.T) {
+	r, mr := newTestRedis(t)
+	ctx := context.Background()
+
+	owner := r.NewLock("job", WithRenewInterval(0))
+	other := r.NewLock("job", WithRenewInterval(0))
+	if err := owner.TryLock(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := other.TryLock(ctx); !errors.Is(err, ErrLockNotAcquired) {
+		t.Fatalf("second TryLock = %v, want ErrLockNotAcquired", err)
+	}
+	// 未持有锁的一方不能释放
+	if err := other.Unlock(ctx); !errors.Is(err, ErrLockLost) {
+		t.Fatalf("non-owner Unlock = %v, want ErrLockLost", err)
+	}
+	if !mr.Exists("job") {
+		t.Fatal("non-owner Unlock released the lock")
+	}
+
+	// 租约过期后被他人获取，原持有者释放时不能删除他人的锁
+	mr.FastForward(time.Minute)
+	if err := other.TryLock(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := owner.Extend(ctx, time.Minute); !errors.Is(err, ErrLockLost) {
+		t.Fatalf("stale Extend = %v, want ErrLockLost", err)
+	}
+	if err := owner.Unlock(ctx); !errors.Is(err, ErrLockLost) {
+		t.Fatalf("stale Unlock = %v, want ErrLockLost", err)
+	}
+	if !mr.Exists("job") {
+		t.Fatal("stale owner released the new holder's lock")
+	}
+
+	if err := other.Unlock(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if mr.Exists("job") {
+		t.Fatal("lock not released")
+	}
+}
+
+func TestLockBlocking(t *testing.T) {
+	r, _ := newTestRedis(t)
+	ctx := context.Background()
+
+	holder := r.NewLock("job", WithRenewInterval(0))
+	if err := holder.TryLock(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	waiter := r.NewLock("job", WithRenewInterval(0), WithRetryBackoff(5*time.Millisecond, 20*time.Millisecond))
+	timeoutCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	if err := waiter.Lock(timeoutCtx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Lock while held = %v, want DeadlineExceeded", err)
+	}
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		_ = holder.Unlock(ctx)
+	}()
+	if err := waiter.Lock(ctx); err != nil {
+		t.Fatal(err)
+	}
+	_ = waiter.Unlock(ctx)
+}
+
+func TestLockWatchdog(t *testing.T) {
+	r, mr := newTestRedis(t)
+	ctx := context.Background()
+
+	l := r.NewLock("job", WithLockTTL(time.Second), WithRenewInterval(20*time.Millisecond))
+	if err := l.TryLock(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// 看门狗将租约重置为完整时长
+	mr.FastForward(800 * time.Millisecond)
+	waitFor(t, func() bool { return mr.TTL("job") > 500*time.Millisecond })
+
+	// 键被他人改写后续期失败，Lost 通道关闭
+	mr.Set("job", "someone-else")
+	select {
+	case <-l.Lost():
+	case <-time.After(2 * time.Second):
+		t.Fatal("Lost not closed after the lock was taken over")
+	}
+	if err := l.Unlock(ctx); !errors.Is(err, ErrLockLost) {
+		t.Fatalf("Unlock after loss = %v, want ErrLockLost", err)
+	}
+}
+
+func TestWithLock(t *testing.T) {
+	r, mr := newTestRedis(t)
+	ctx := context.Background()
+
+	if err := r.WithLock(ctx, "job", func(ctx context.Context) error {
+		if !mr.Exists("job") {
+			t.Error("lock not held inside fn")
+		}
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if mr.Exists("job") {
+		t.Fatal("lock not released after fn")
+	}
+
+	// 租约丢失时 fn 的 ctx 被取消，cause 为 ErrLockLost
+	err := r.WithLock(ctx, "job", func(ctx context.Context) error {
+		mr.Set("job", "someone-else")
+		select {
+		case <-ctx.Done():
+			return context.Cause(ctx)
+		case <-time.After(2 * time.Second):
+			return errors.New("fn ctx not cancelled")
+		}
+	}, WithLockTTL(time.Second), WithRenewInterval(10*time.Millisecond))
+	if !errors.Is(err, ErrLockLost) {
+		t.Fatalf("WithLock = %v, want ErrLockLost", err)
+	}
+	if v, _ := mr.Get("job"); v != "someone-else" {
+		t.Fatalf("WithLock released the new holder's lock: %q", v)
+	}
+}
+
+// waitFor 轮询直到 cond 成立或超时
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
@@ -0,0 +1,139 @@
+package cache
+
+import (
+	"container/list"
+	"hash/fnv"
+	"sync"
+	"time"
+)
+
+// genSlots 代数计数器的槽数，键按哈希分到各槽，内存占用与键的数量无关
+const genSlots = 1024
+
+// lru 按条数与过期时间限制的进程内 LRU 缓存
+type lru struct {
+	mu         sync.Mutex
+	maxEntries int
+	ll         *list.List
+	items      map[string]*list.Element
+	evictions  uint64
+
+	// gens 键的代数，remove 与 purge 时递增；读取远端前记录代数，
+	// 写入本地前代数已变化说明期间发生过失效，放弃写入
+	gens [genSlots]uint64
+}
+
+type lruEntry struct {
+	key      string
+	value    string
+	expireAt time.Time
+}
+
+func newLRU(maxEntries int) *lru {
+	return &lru{
+		maxEntries: maxEntries,
+		ll:         list.New(),
+		items:      make(map[string]*list.Element),
+	}
+}
+
+// get 返回未过期的值，过期的条目会被删除
+func (c *lru) get(key string, now time.Time) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[key]
+	if !ok {
+		return "", false
+	}
+	e := el.Value.(*lruEntry)
+	if !now.Before(e.expireAt) {
+		c.removeElement(el)
+		return "", false
+	}
+	c.ll.MoveToFront(el)
+	return e.value, true
+}
+
+// set 写入值，超出条数上限时淘汰最久未使用的条目
+func (c *lru) set(key, value string, expireAt time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.setLocked(key, value, expireAt)
+}
+
+func (c *lru) setLocked(key, value string, expireAt time.Time) {
+	if el, ok := c.items[key]; ok {
+		e := el.Value.(*lruEntry)
+		e.value, e.expireAt = value, expireAt
+		c.ll.MoveToFront(el)
+		return
+	}
+
+	c.items[key] = c.ll.PushFront(&lruEntry{key: key, value: value, expireAt: expireAt})
+	for c.maxEntries > 0 && c.ll.Len() > c.maxEntries {
+		c.removeElement(c.ll.Back())
+		c.evictions++
+	}
+}
+
+// generation 返回键当前的代数
+func (c *lru) generation(key string) uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.gens[genSlot(key)]
+}
+
+// setIfGeneration 仅当键的代数仍为 gen 时写入，返回是否写入
+func (c *lru) setIfGeneration(key, value string, expireAt time.Time, gen uint64) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.gens[genSlot(key)] != gen {
+		return false
+	}
+	c.setLocked(key, value, expireAt)
+	return true
+}
+
+// remove 删除指定的键并递增其代数
+func (c *lru) remove(keys ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, key := range keys {
+		c.gens[genSlot(key)]++
+		if el, ok := c.items[key]; ok {
+			c.removeElement(el)
+		}
+	}
+}
+
+// purge 清空缓存并递增所有键的代数
+func (c *lru) purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.ll.Init()
+	c.items = make(map[string]*list.Element)
+	for i := range c.gens {
+		c.gens[i]++
+	}
+}
+
+// stats 返回当前条数与累计淘汰数
+func (c *lru) stats() (int, uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ll.Len(), c.evictions
+}
+
+func (c *lru) removeElement(el *list.Element) {
+	c.ll.Remove(el)
+	delete(c.items, el.Value.(*lruEntry).key)
+}
+
+func genSlot(key string) uint32 {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+	return h.Sum32() % genSlots
+}
@@ -0,0 +1,59 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLRU(t *testing.T) {
+	c := newLRU(2)
+	now := time.Now()
+	later := now.Add(time.Minute)
+
+	c.set("a", "1", later)
+	c.set("b", "2", later)
+	if _, ok := c.get("a", now); !ok {
+		t.Fatal("a missing")
+	}
+	c.set("c", "3", later) // 淘汰最久未使用的 b
+	if _, ok := c.get("b", now); ok {
+		t.Error("b should be evicted")
+	}
+	if v, ok := c.get("a", now); !ok || v != "1" {
+		t.Errorf("a = %q, %v", v, ok)
+	}
+
+	if _, ok := c.get("c", later); ok {
+		t.Error("c should be expired")
+	}
+	c.remove("a")
+	if entries, evictions := c.stats(); entries != 0 || evictions != 1 {
+		t.Errorf("stats = %d entries, %d evictions, want 0, 1", entries, evictions)
+	}
+}
+
+func TestLRUGeneration(t *testing.T) {
+	c := newLRU(0)
+	later := time.Now().Add(time.Minute)
+
+	gen := c.generation("a")
+	c.remove("a") // 读取远端期间键被失效
+	if c.setIfGeneration("a", "stale", later, gen) {
+		t.Fatal("stale value stored after invalidation")
+	}
+
+	gen = c.generation("a")
+	if !c.setIfGeneration("a", "fresh", later, gen) {
+		t.Fatal("value not stored")
+	}
+	gen = c.generation("a")
+	c.purge()
+	if c.setIfGeneration("a", "stale", later, gen) {
+		t.Fatal("stale value stored after purge")
+	}
+}
+
+func TestCacheInterface(t *testing.T) {
+	var _ Cache = (*Redis)(nil)
+	var _ Cache = (*TwoLevel)(nil)
+}
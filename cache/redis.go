@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/go-redis/redis/v8"
+	"golang.org/x/sync/singleflight"
 )
 
 // RedisConfig Redis配置
@@ -31,14 +32,21 @@ func DefaultRedisConfig() *RedisConfig {
 // Redis Redis客户端封装
 type Redis struct {
 	client redis.UniversalClient
+	group  singleflight.Group // GetOrLoad 的并发加载去重
+	stats  *poolStats         // 连接池统计采集器，未启用指标时为 nil
 }
 
-// NewRedis 创建Redis客户端
-func NewRedis(cfg *RedisConfig) (*Redis, error) {
+// NewRedis 创建Redis客户端，可通过 WithMetrics、WithTracing 等选项启用观测能力
+func NewRedis(cfg *RedisConfig, opts ...RedisOption) (*Redis, error) {
 	if cfg == nil {
 		cfg = DefaultRedisConfig()
 	}
 
+	o := &redisOptions{name: "default"}
+	for _, opt := range opts {
+		opt(o)
+	}
+
 	// 创建通用客户端（自动判断单机/集群模式）
 	client := redis.NewUniversalClient(&redis.UniversalOptions{
 		Addrs:      cfg.Addrs,
@@ -48,15 +56,28 @@ func NewRedis(cfg *RedisConfig) (*Redis, error) {
 		MaxRetries: cfg.MaxRetries,
 	})
 
+	// 安装指标与追踪钩子
+	hook, stats := newRedisHook(client, o)
+	if hook != nil {
+		client.AddHook(hook)
+	}
+
 	// 测试连接
 	ctx, cancel := context.WithTimeout(context.Background(), cfg.Timeout)
 	defer cancel()
 
 	if err := client.Ping(ctx).Err(); err != nil {
+		stats.unregister()
+		_ = client.Close()
 		return nil, err
 	}
 
-	return &Redis{client: client}, nil
+	return &Redis{client: client, stats: stats}, nil
+}
+
+// Ping 检查Redis连接是否可用
+func (r *Redis) Ping(ctx context.Context) error {
+	return r.client.Ping(ctx).Err()
 }
 
 // Get 获取缓存
@@ -130,8 +151,9 @@ func (r *Redis) ScanKeys(ctx context.Context, pattern string) ([]string, error)
 	return keys, nil
 }
 
-// Close 关闭连接
+// Close 关闭连接并注销连接池统计指标
 func (r *Redis) Close() error {
+	r.stats.unregister()
 	return r.client.Close()
 }
 
@@ -0,0 +1,21 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+// newTestRedis 启动进程内的 miniredis 并创建客户端
+func newTestRedis(t *testing.T) (*Redis, *miniredis.Miniredis) {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+	r, err := NewRedis(&RedisConfig{Addrs: []string{mr.Addr()}, PoolSize: 10, Timeout: time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = r.Close() })
+	return r, mr
+}
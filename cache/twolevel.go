@@ -0,0 +1,255 @@
+package cache
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// Cache Redis 与 TwoLevel 共同的键值接口，业务代码依赖该接口即可在两者之间切换
+type Cache interface {
+	Get(ctx context.Context, key string) (string, error)
+	Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error
+	Del(ctx context.Context, keys ...string) error
+	Exists(ctx context.Context, keys ...string) (int64, error)
+	Expire(ctx context.Context, key string, expiration time.Duration) error
+	ExpireAt(ctx context.Context, key string, expiration time.Time) error
+	TTL(ctx context.Context, key string) (time.Duration, error)
+}
+
+// LocalConfig 本地缓存配置
+type LocalConfig struct {
+	MaxEntries int           // 最大条数，为 0 时使用默认值
+	TTL        time.Duration // 本地条目的最长存活时间（不超过键在 Redis 中的剩余时间），为 0 时使用默认值
+	Channel    string        // 失效通知的 Pub/Sub 频道，为空时使用默认值
+}
+
+// DefaultLocalConfig 返回默认本地缓存配置
+func DefaultLocalConfig() *LocalConfig {
+	return &LocalConfig{
+		MaxEntries: 10000,
+		TTL:        time.Minute,
+		Channel:    "cache:invalidate",
+	}
+}
+
+// LocalStats 本地缓存统计
+type LocalStats struct {
+	Hits          uint64 // 本地命中
+	Misses        uint64 // 本地未命中（随后读取 Redis）
+	RemoteHits    uint64 // 本地未命中但 Redis 命中
+	RemoteMisses  uint64 // Redis 也未命中
+	Evictions     uint64 // 因超出条数上限被淘汰的条目
+	Invalidations uint64 // 收到的其他实例的失效通知
+	Entries       int    // 当前条数
+}
+
+// TwoLevel 在 Redis 前增加进程内 LRU 缓存的两级缓存。
+// 通过 TwoLevel 写入或删除键时会清除本实例的本地条目并经 Pub/Sub 通知其他实例清除；
+// 直接写 Redis 的修改在本地条目过期（LocalConfig.TTL）前不可见，可调用 Invalidate 主动通知
+type TwoLevel struct {
+	remote  *Redis
+	local   *lru
+	ttl     time.Duration
+	channel string
+	origin  string // 本实例标识，用于忽略自己发出的通知
+
+	hits, misses, remoteHits, remoteMisses, invalidations atomic.Uint64
+
+	pubsub *redis.PubSub
+	done   chan struct{}
+	once   sync.Once
+}
+
+// invalidation 失效通知
+type invalidation struct {
+	Origin string   `json:"origin"`
+	Keys   []string `json:"keys"`
+}
+
+// NewTwoLevel 创建两级缓存并订阅失效通知，cfg 为空时使用默认配置，未设置（为 0）的字段取默认值
+func NewTwoLevel(ctx context.Context, remote *Redis, cfg *LocalConfig) (*TwoLevel, error) {
+	def := DefaultLocalConfig()
+	if cfg == nil {
+		cfg = def
+	}
+	maxEntries, ttl, channel := cfg.MaxEntries, cfg.TTL, cfg.Channel
+	if maxEntries <= 0 {
+		maxEntries = def.MaxEntries
+	}
+	if ttl <= 0 {
+		ttl = def.TTL
+	}
+	if channel == "" {
+		channel = def.Channel
+	}
+	origin := make([]byte, 8)
+	if _, err := rand.Read(origin); err != nil {
+		return nil, err
+	}
+
+	t := &TwoLevel{
+		remote:  remote,
+		local:   newLRU(maxEntries),
+		ttl:     ttl,
+		channel: channel,
+		origin:  hex.EncodeToString(origin),
+		done:    make(chan struct{}),
+	}
+
+	// 等待订阅确认，保证返回后不会漏掉通知
+	t.pubsub = remote.Subscribe(ctx, channel)
+	if _, err := t.pubsub.Receive(ctx); err != nil {
+		t.pubsub.Close()
+		return nil, err
+	}
+	go t.listen()
+
+	return t, nil
+}
+
+// listen 处理失效通知；连接断开后重新订阅时清空本地缓存，避免断线期间漏掉的通知导致脏读
+func (t *TwoLevel) listen() {
+	defer close(t.done)
+
+	for msg := range t.pubsub.ChannelWithSubscriptions(context.Background(), 100) {
+		switch m := msg.(type) {
+		case *redis.Subscription:
+			if m.Kind == "subscribe" {
+				t.local.purge()
+			}
+		case *redis.Message:
+			var inv invalidation
+			if err := json.Unmarshal([]byte(m.Payload), &inv); err != nil || inv.Origin == t.origin {
+				continue
+			}
+			t.local.remove(inv.Keys...)
+			t.invalidations.Add(1)
+		}
+	}
+}
+
+// Get 优先读取本地缓存，未命中时读取 Redis 并写入本地缓存。
+// 本地条目的存活时间不超过键在 Redis 中的剩余时间；读取 Redis 期间键被失效时不写入本地缓存
+func (t *TwoLevel) Get(ctx context.Context, key string) (string, error) {
+	if v, ok := t.local.get(key, time.Now()); ok {
+		t.hits.Add(1)
+		return v, nil
+	}
+	t.misses.Add(1)
+
+	gen := t.local.generation(key)
+	pipe := t.remote.client.Pipeline()
+	get := pipe.Get(ctx, key)
+	pttl := pipe.PTTL(ctx, key)
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return "", err
+	}
+	v, err := get.Result()
+	if err != nil {
+		if err == redis.Nil {
+			t.remoteMisses.Add(1)
+		}
+		return "", err
+	}
+	t.remoteHits.Add(1)
+
+	ttl := t.ttl
+	if d := pttl.Val(); d > 0 && d < ttl {
+		ttl = d
+	}
+	t.local.setIfGeneration(key, v, time.Now().Add(ttl), gen)
+	return v, nil
+}
+
+// Set 写入 Redis 并使各实例的本地条目失效
+func (t *TwoLevel) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+	if err := t.remote.Set(ctx, key, value, expiration); err != nil {
+		return err
+	}
+	return t.Invalidate(ctx, key)
+}
+
+// Del 删除 Redis 中的键并使各实例的本地条目失效
+func (t *TwoLevel) Del(ctx context.Context, keys ...string) error {
+	if err := t.remote.Del(ctx, keys...); err != nil {
+		return err
+	}
+	return t.Invalidate(ctx, keys...)
+}
+
+// Exists 检查键是否存在（读取 Redis）
+func (t *TwoLevel) Exists(ctx context.Context, keys ...string) (int64, error) {
+	return t.remote.Exists(ctx, keys...)
+}
+
+// Expire 设置过期时间并使各实例的本地条目失效
+func (t *TwoLevel) Expire(ctx context.Context, key string, expiration time.Duration) error {
+	if err := t.remote.Expire(ctx, key, expiration); err != nil {
+		return err
+	}
+	return t.Invalidate(ctx, key)
+}
+
+// ExpireAt 设置过期时间并使各实例的本地条目失效
+func (t *TwoLevel) ExpireAt(ctx context.Context, key string, expiration time.Time) error {
+	if err := t.remote.ExpireAt(ctx, key, expiration); err != nil {
+		return err
+	}
+	return t.Invalidate(ctx, key)
+}
+
+// TTL 获取剩余过期时间（读取 Redis）
+func (t *TwoLevel) TTL(ctx context.Context, key string) (time.Duration, error) {
+	return t.remote.TTL(ctx, key)
+}
+
+// Invalidate 清除本实例的本地条目并通知其他实例清除，用于绕过 TwoLevel 直接修改 Redis 之后
+func (t *TwoLevel) Invalidate(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	t.local.remove(keys...)
+
+	payload, err := json.Marshal(invalidation{Origin: t.origin, Keys: keys})
+	if err != nil {
+		return err
+	}
+	return t.remote.Publish(ctx, t.channel, payload)
+}
+
+// Stats 返回本地缓存统计
+func (t *TwoLevel) Stats() LocalStats {
+	entries, evictions := t.local.stats()
+	return LocalStats{
+		Hits:          t.hits.Load(),
+		Misses:        t.misses.Load(),
+		RemoteHits:    t.remoteHits.Load(),
+		RemoteMisses:  t.remoteMisses.Load(),
+		Evictions:     evictions,
+		Invalidations: t.invalidations.Load(),
+		Entries:       entries,
+	}
+}
+
+// Remote 返回底层的 Redis 客户端
+func (t *TwoLevel) Remote() *Redis {
+	return t.remote
+}
+
+// Close 取消订阅并清空本地缓存，不关闭底层 Redis 客户端
+func (t *TwoLevel) Close() error {
+	var err error
+	t.once.Do(func() {
+		err = t.pubsub.Close()
+		<-t.done
+		t.local.purge()
+	})
+	return err
+}
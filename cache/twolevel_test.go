@@ -0,0 +1,142 @@
+package cache
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+func newTestTwoLevel(t *testing.T, r *Redis, cfg *LocalConfig) *TwoLevel {
+	t.Helper()
+
+	tl, err := NewTwoLevel(context.Background(), r, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = tl.Close() })
+	return tl
+}
+
+func TestTwoLevelGetSet(t *testing.T) {
+	r, mr := newTestRedis(t)
+	tl := newTestTwoLevel(t, r, nil)
+	ctx := context.Background()
+
+	if _, err := tl.Get(ctx, "k"); err != redis.Nil {
+		t.Fatalf("Get missing key = %v, want redis.Nil", err)
+	}
+	if err := tl.Set(ctx, "k", "v1", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if v, err := tl.Get(ctx, "k"); err != nil || v != "v1" {
+			t.Fatalf("Get = %q, %v", v, err)
+		}
+	}
+
+	// 绕过 TwoLevel 修改 Redis，本地条目仍是旧值，Invalidate 之后读到新值
+	mr.Set("k", "v2")
+	if v, _ := tl.Get(ctx, "k"); v != "v1" {
+		t.Fatalf("Get = %q, want cached v1", v)
+	}
+	if err := tl.Invalidate(ctx, "k"); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := tl.Get(ctx, "k"); v != "v2" {
+		t.Fatalf("Get after Invalidate = %q, want v2", v)
+	}
+
+	if err := tl.Del(ctx, "k"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tl.Get(ctx, "k"); err != redis.Nil {
+		t.Fatalf("Get after Del = %v, want redis.Nil", err)
+	}
+
+	want := LocalStats{Hits: 2, Misses: 4, RemoteHits: 2, RemoteMisses: 2, Entries: 0}
+	if got := tl.Stats(); got != want {
+		t.Fatalf("Stats = %+v, want %+v", got, want)
+	}
+}
+
+func TestTwoLevelInvalidateAcrossInstances(t *testing.T) {
+	r, _ := newTestRedis(t)
+	a := newTestTwoLevel(t, r, nil)
+	b := newTestTwoLevel(t, r, nil)
+	ctx := context.Background()
+
+	// 每次 Set 都会发出通知，等待 b 收到后再继续，避免迟到的通知被误认为后一次的
+	waitInvalidations := func(n uint64) {
+		t.Helper()
+		deadline := time.Now().Add(2 * time.Second)
+		for b.Stats().Invalidations < n {
+			if time.Now().After(deadline) {
+				t.Fatalf("received %d invalidations, want %d", b.Stats().Invalidations, n)
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+
+	if err := a.Set(ctx, "k", "v1", 0); err != nil {
+		t.Fatal(err)
+	}
+	waitInvalidations(1)
+	if v, _ := b.Get(ctx, "k"); v != "v1" {
+		t.Fatalf("b.Get = %q", v)
+	}
+	if err := a.Set(ctx, "k", "v2", 0); err != nil {
+		t.Fatal(err)
+	}
+	waitInvalidations(2)
+	if v, _ := b.Get(ctx, "k"); v != "v2" {
+		t.Fatalf("b.Get after invalidation = %q, want v2", v)
+	}
+	// 自己发出的通知不计入
+	if n := a.Stats().Invalidations; n != 0 {
+		t.Fatalf("a received %d own invalidations", n)
+	}
+}
+
+func TestTwoLevelLocalTTL(t *testing.T) {
+	r, mr := newTestRedis(t)
+
+	// TTL 为 0 时使用默认值
+	tl := newTestTwoLevel(t, r, &LocalConfig{MaxEntries: 10})
+	if tl.ttl != DefaultLocalConfig().TTL || tl.channel != DefaultLocalConfig().Channel {
+		t.Fatalf("ttl = %v, channel = %q", tl.ttl, tl.channel)
+	}
+
+	// 本地条目不超过键在 Redis 中的剩余时间
+	ctx := context.Background()
+	mr.Set("k", "v")
+	mr.SetTTL("k", 30*time.Millisecond)
+	if _, err := tl.Get(ctx, "k"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := tl.local.get("k", time.Now().Add(40*time.Millisecond)); ok {
+		t.Fatal("local entry outlives the redis ttl")
+	}
+}
+
+func TestTwoLevelDefaultMaxEntries(t *testing.T) {
+	r, _ := newTestRedis(t)
+
+	// 只设置 TTL 时条数上限取默认值，本地缓存仍有界
+	tl := newTestTwoLevel(t, r, &LocalConfig{TTL: time.Minute})
+	limit := DefaultLocalConfig().MaxEntries
+	expireAt := time.Now().Add(time.Minute)
+	for i := 0; i <= limit; i++ {
+		tl.local.set(strconv.Itoa(i), "v", expireAt)
+	}
+
+	stats := tl.Stats()
+	if stats.Entries != limit || stats.Evictions != 1 {
+		t.Fatalf("Entries = %d, Evictions = %d, want %d and 1", stats.Entries, stats.Evictions, limit)
+	}
+	if _, ok := tl.local.get("0", time.Now()); ok {
+		t.Fatal("oldest entry not evicted")
+	}
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffLine 差异中的一行，op 为 ' '（未变）、'+'（新增）或 '-'（删除）
+type diffLine struct {
+	op   byte
+	text string
+}
+
+// diffContext 变更行前后输出的上下文行数
+const diffContext = 2
+
+// lineDiff 生成两段文本按行比较的差异（基于最长公共子序列），只输出变更行及其上下文
+func lineDiff(name, a, b string) string {
+	lines := diffLines(splitLines(a), splitLines(b))
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- %s\n+++ %s\n", name, name)
+	last := -1
+	for k, l := range lines {
+		if l.op == ' ' && !changedNear(lines, k) {
+			continue
+		}
+		if last >= 0 && k > last+1 {
+			sb.WriteString("...\n")
+		}
+		fmt.Fprintf(&sb, "%c %s\n", l.op, l.text)
+		last = k
+	}
+	return sb.String()
+}
+
+// diffLines 计算两组行之间的编辑序列
+func diffLines(oldLines, newLines []string) []diffLine {
+	// lcs[i][j] 为 oldLines[i:] 与 newLines[j:] 的最长公共子序列长度
+	lcs := make([][]int, len(oldLines)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(newLines)+1)
+	}
+	for i := len(oldLines) - 1; i >= 0; i-- {
+		for j := len(newLines) - 1; j >= 0; j-- {
+			if oldLines[i] == newLines[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var lines []diffLine
+	i, j := 0, 0
+	for i < len(oldLines) || j < len(newLines) {
+		switch {
+		case i < len(oldLines) && j < len(newLines) && oldLines[i] == newLines[j]:
+			lines = append(lines, diffLine{' ', oldLines[i]})
+			i++
+			j++
+		case i < len(oldLines) && (j == len(newLines) || lcs[i+1][j] >= lcs[i][j+1]):
+			lines = append(lines, diffLine{'-', oldLines[i]})
+			i++
+		default:
+			lines = append(lines, diffLine{'+', newLines[j]})
+			j++
+		}
+	}
+	return lines
+}
+
+// changedNear 判断第 k 行前后 diffContext 行内是否有变更
+func changedNear(lines []diffLine, k int) bool {
+	for idx := k - diffContext; idx <= k+diffContext; idx++ {
+		if idx >= 0 && idx < len(lines) && lines[idx].op != ' ' {
+			return true
+		}
+	}
+	return false
+}
+
+// splitLines 按行拆分文本，忽略末尾换行
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
@@ -0,0 +1,322 @@
+// Command genmodel 根据数据库表结构生成 GORM 模型
+//
+// 用法：
+//
+//	genmodel -dsn "user:pass@tcp(127.0.0.1:3306)/app?parseTime=true" -out ./internal/model -pkg model
+//	genmodel -dialect sqlite -dsn ./app.db -include "user_*" -exclude "*_tmp" -dry-run -diff
+//	genmodel -dsn "$DSN" -templates model,repository,dto,converter -out ./internal/model \
+//		-repo-out ./internal/repository -dto-out ./internal/dto -import-base github.com/acme/app
+//	genmodel -dsn "$DSN" -out ./internal/model -check -format json
+//
+// -check 模式比较 -out 目录中已有的模型与数据库表结构，存在差异时退出码为 3，便于在 CI 中使用。
+// 列的 Go 类型按本次的 -nullable 等类型参数推导，需与生成模型时使用的参数一致，否则会报告不存在的类型差异。
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/shrimps80/go-service-utils/database"
+	"github.com/shrimps80/go-service-utils/genmodel"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+// options 命令行参数
+type options struct {
+	dsn      string
+	dialect  string
+	include  string
+	exclude  string
+	out      string
+	pkg      string
+	jsonCase string
+	nullable string
+	binding  bool
+
+	templates  string
+	repoOut    string
+	dtoOut     string
+	importBase string
+
+	dryRun bool
+	diff   bool
+	force  bool
+
+	check  bool
+	format string
+}
+
+// exitDrift -check 发现模型与数据库不一致时的退出码
+const exitDrift = 3
+
+func run(args []string) int {
+	var opts options
+	fs := flag.NewFlagSet("genmodel", flag.ContinueOnError)
+	fs.StringVar(&opts.dsn, "dsn", "", "数据源名称（必填）")
+	fs.StringVar(&opts.dialect, "dialect", "mysql", "数据库类型：mysql, postgres, sqlite")
+	fs.StringVar(&opts.include, "include", "", "只生成匹配的表，逗号分隔的通配符，如 user_*,orders")
+	fs.StringVar(&opts.exclude, "exclude", "", "跳过匹配的表，逗号分隔的通配符")
+	fs.StringVar(&opts.out, "out", "./model", "输出目录")
+	fs.StringVar(&opts.pkg, "pkg", "model", "生成代码的包名")
+	fs.StringVar(&opts.jsonCase, "json-case", genmodel.JSONCaseSnake, "json 标签命名风格：snake, camel, none")
+	fs.StringVar(&opts.nullable, "nullable", genmodel.NullablePointer, "可空列的映射方式：pointer, sql_null, ignore")
+	fs.BoolVar(&opts.binding, "binding", false, "生成 binding 校验标签")
+	fs.StringVar(&opts.templates, "templates", genmodel.TemplateModel, "生成的模板，逗号分隔：model, repository, dto, converter")
+	fs.StringVar(&opts.repoOut, "repo-out", "", "repository 模板的输出目录，默认同 -out")
+	fs.StringVar(&opts.dtoOut, "dto-out", "", "dto 与 converter 模板的输出目录，默认同 -out")
+	fs.StringVar(&opts.importBase, "import-base", "", "当前目录对应的导入路径（如 github.com/acme/app），输出目录不同时用于跨包引用，默认根据 go.mod 推导")
+	fs.BoolVar(&opts.dryRun, "dry-run", false, "只显示将要变更的文件，不写入")
+	fs.BoolVar(&opts.diff, "diff", false, "显示每个文件的变更内容")
+	fs.BoolVar(&opts.force, "force", false, "覆盖被手工修改过的文件")
+	fs.BoolVar(&opts.check, "check", false, "不生成代码，比较 -out 中的模型与数据库表结构")
+	fs.StringVar(&opts.format, "format", "text", "-check 的报告格式：text, json")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if opts.dsn == "" {
+		fmt.Fprintln(os.Stderr, "genmodel: -dsn is required")
+		fs.Usage()
+		return 2
+	}
+
+	if opts.check {
+		drift, err := check(&opts)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "genmodel:", err)
+			return 1
+		}
+		if drift {
+			return exitDrift
+		}
+		return 0
+	}
+
+	if err := generate(&opts); err != nil {
+		fmt.Fprintln(os.Stderr, "genmodel:", err)
+		return 1
+	}
+	return 0
+}
+
+// newGenerator 连接数据库并按参数创建 Generator，返回的 close 用于关闭连接
+func newGenerator(opts *options) (*genmodel.Generator, func(), error) {
+	templates, err := templateSpecs(opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	config := &genmodel.Config{
+		OutputPath:  opts.out,
+		PackageName: opts.pkg,
+		Types:       genmodel.TypeOptions{Nullable: opts.nullable},
+		JSONCase:    opts.jsonCase,
+		BindingTags: opts.binding,
+		Include:     splitList(opts.include),
+		Exclude:     splitList(opts.exclude),
+		Force:       opts.force,
+		Templates:   templates,
+	}
+	if err := config.Validate(); err != nil {
+		return nil, nil, err
+	}
+
+	cfg := database.DefaultConfig()
+	cfg.Type = opts.dialect
+	cfg.DSN = opts.dsn
+	cfg.LogLevel = "silent"
+	db, err := database.New(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	config.DB = db
+	return genmodel.NewGenerator(config), func() { database.Close(db) }, nil
+}
+
+// check 比较已有模型与数据库表结构并输出报告，返回是否存在差异
+func check(opts *options) (bool, error) {
+	if opts.format != "text" && opts.format != "json" {
+		return false, fmt.Errorf("unknown report format %q", opts.format)
+	}
+
+	models, err := genmodel.LoadModels(opts.out)
+	if err != nil {
+		return false, err
+	}
+	g, closeDB, err := newGenerator(opts)
+	if err != nil {
+		return false, err
+	}
+	defer closeDB()
+
+	diff, err := g.Diff(models)
+	if err != nil {
+		return false, err
+	}
+	if opts.format == "json" {
+		err = diff.WriteJSON(os.Stdout)
+	} else {
+		err = diff.WriteText(os.Stdout)
+	}
+	return diff.HasChanges(), err
+}
+
+// generate 生成所有匹配的表，手工修改过的文件被跳过并在最后汇总报错
+func generate(opts *options) error {
+	g, closeDB, err := newGenerator(opts)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	tables, err := g.Tables()
+	if err != nil {
+		return err
+	}
+
+	var skipped []string
+	for _, table := range tables {
+		files, err := g.Render(table)
+		if err != nil {
+			return err
+		}
+
+		for _, f := range files {
+			state, old, err := genmodel.InspectFile(f.Path)
+			if err != nil {
+				return err
+			}
+
+			action := "update"
+			switch {
+			case state == genmodel.FileMissing:
+				action = "create"
+			case string(old) == string(f.Content):
+				fmt.Printf("unchanged %s\n", f.Path)
+				continue
+			case (state == genmodel.FileModified || state == genmodel.FileManual) && !opts.force:
+				fmt.Printf("skip      %s (edited by hand, use -force to overwrite)\n", f.Path)
+				skipped = append(skipped, f.Path)
+				continue
+			}
+
+			fmt.Printf("%-9s %s\n", action, f.Path)
+			if opts.diff {
+				fmt.Print(lineDiff(f.Path, string(old), string(f.Content)))
+			}
+			if opts.dryRun {
+				continue
+			}
+			if err := g.WriteFile(f); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(skipped) > 0 {
+		return fmt.Errorf("skipped %d file(s) edited by hand: %s", len(skipped), strings.Join(skipped, ", "))
+	}
+	return nil
+}
+
+// templateSpecs 根据参数构造模板列表：converter 与 dto 输出到同一目录，目录名即包名（-out 使用 -pkg）。
+// 导入路径优先由 -import-base 拼接，否则根据所在模块的 go.mod 推导；输出到多个目录却无法确定导入路径时报错
+func templateSpecs(opts *options) ([]genmodel.Template, error) {
+	dirOf := map[string]string{
+		genmodel.TemplateModel:      opts.out,
+		genmodel.TemplateRepository: opts.repoOut,
+		genmodel.TemplateDTO:        opts.dtoOut,
+		genmodel.TemplateConverter:  opts.dtoOut,
+	}
+
+	var specs []genmodel.Template
+	crossPackage := false
+	for _, name := range splitList(opts.templates) {
+		dir := dirOf[name]
+		if dir == "" {
+			dir = opts.out
+		}
+
+		spec := genmodel.Template{Name: name, OutputPath: dir, Package: opts.pkg}
+		if filepath.Clean(dir) != filepath.Clean(opts.out) {
+			spec.Package = filepath.Base(dir)
+			crossPackage = true
+		}
+		specs = append(specs, spec)
+	}
+	if !crossPackage {
+		return specs, nil
+	}
+
+	for i := range specs {
+		dir := filepath.Clean(specs[i].OutputPath)
+		if opts.importBase != "" {
+			specs[i].ImportPath = path.Join(opts.importBase, filepath.ToSlash(dir))
+			continue
+		}
+		importPath, err := moduleImportPath(dir)
+		if err != nil {
+			return nil, fmt.Errorf("cannot determine import path of %s (set -import-base): %w", dir, err)
+		}
+		specs[i].ImportPath = importPath
+	}
+	return specs, nil
+}
+
+// moduleImportPath 根据上层目录中的 go.mod 推导目录的导入路径
+func moduleImportPath(dir string) (string, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+
+	for root := abs; ; root = filepath.Dir(root) {
+		data, err := os.ReadFile(filepath.Join(root, "go.mod"))
+		if err == nil {
+			module := modulePath(data)
+			if module == "" {
+				return "", fmt.Errorf("no module directive in %s", filepath.Join(root, "go.mod"))
+			}
+			rel, err := filepath.Rel(root, abs)
+			if err != nil {
+				return "", err
+			}
+			return path.Join(module, filepath.ToSlash(rel)), nil
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return "", err
+		}
+		if filepath.Dir(root) == root {
+			return "", errors.New("go.mod not found")
+		}
+	}
+}
+
+// modulePath 读取 go.mod 中的 module 指令
+func modulePath(gomod []byte) string {
+	for _, line := range strings.Split(string(gomod), "\n") {
+		line = strings.TrimSpace(line)
+		if rest, ok := strings.CutPrefix(line, "module"); ok && (rest == "" || rest[0] == ' ' || rest[0] == '\t') {
+			return strings.Trim(strings.TrimSpace(rest), `"`)
+		}
+	}
+	return ""
+}
+
+// splitList 拆分逗号分隔的参数
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
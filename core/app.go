@@ -0,0 +1,258 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shrimps80/go-service-utils/logger"
+	"github.com/shrimps80/go-service-utils/middleware"
+)
+
+// AppOptions 定义应用运行的配置选项
+type AppOptions struct {
+	Addr            string         // HTTP监听地址
+	ReadTimeout     time.Duration  // 读取请求超时
+	WriteTimeout    time.Duration  // 写入响应超时
+	IdleTimeout     time.Duration  // keep-alive空闲超时
+	DrainDelay      time.Duration  // 标记为不健康后、关闭HTTP服务前的等待时间，用于负载均衡摘除流量
+	ShutdownTimeout time.Duration  // 优雅关闭的总超时时间（含排空请求与关闭钩子）
+	Signals         []os.Signal    // 触发优雅关闭的信号
+	Engine          *EngineOptions // Gin引擎配置
+}
+
+// DefaultAppOptions 返回默认的应用配置
+func DefaultAppOptions() *AppOptions {
+	return &AppOptions{
+		Addr:            ":8080",
+		ReadTimeout:     30 * time.Second,
+		WriteTimeout:    30 * time.Second,
+		IdleTimeout:     60 * time.Second,
+		DrainDelay:      5 * time.Second,
+		ShutdownTimeout: 30 * time.Second,
+		Signals:         []os.Signal{syscall.SIGINT, syscall.SIGTERM},
+		Engine:          DefaultEngineOptions(),
+	}
+}
+
+// ShutdownHook 关闭钩子，ctx 携带整体关闭的截止时间
+type ShutdownHook func(ctx context.Context) error
+
+type shutdownHook struct {
+	name string
+	fn   ShutdownHook
+}
+
+// App 服务应用骨架：持有Gin引擎、HTTP服务与服务上下文，负责启动与优雅关闭
+type App struct {
+	opts   *AppOptions
+	engine *gin.Engine
+	health *middleware.HealthMonitor
+	log    *logger.Logger
+	svcCtx *ServiceContext
+	server *http.Server
+
+	mutex    sync.Mutex
+	hooks    []shutdownHook
+	serving  bool // HTTP服务已启动，关闭时需要摘流并关闭HTTP服务
+	stopping bool // 已开始关闭，Run 不再启动HTTP服务
+
+	shutdownOnce sync.Once
+	shutdownErr  error
+}
+
+// NewApp 创建一个新的应用。opts.Engine.Health 为空时应用使用独立的健康检查实例，
+// 包级的 middleware.SetHealthStatus 不影响该应用的探针，需通过 App.Health() 切换；
+// 希望沿用包级开关时将 opts.Engine.Health 设为 middleware.DefaultHealth()
+func NewApp(opts *AppOptions) (*App, error) {
+	if opts == nil {
+		opts = DefaultAppOptions()
+	}
+	if opts.Engine == nil {
+		opts.Engine = DefaultEngineOptions()
+	}
+	if len(opts.Signals) == 0 {
+		opts.Signals = []os.Signal{syscall.SIGINT, syscall.SIGTERM}
+	}
+
+	// 初始化日志
+	log, err := logger.NewLogger(opts.Engine.Log)
+	if err != nil {
+		return nil, err
+	}
+
+	// 每个应用使用独立的健康检查实例，避免同一进程内的多个应用相互影响
+	engineOpts := *opts.Engine
+	if engineOpts.Health == nil {
+		engineOpts.Health = middleware.NewHealth(nil)
+	}
+	engine := newEngine(&engineOpts, log)
+
+	return &App{
+		opts:   opts,
+		engine: engine,
+		health: engineOpts.Health,
+		log:    log,
+		svcCtx: NewServiceContext(),
+		server: &http.Server{
+			Addr:         opts.Addr,
+			Handler:      engine,
+			ReadTimeout:  opts.ReadTimeout,
+			WriteTimeout: opts.WriteTimeout,
+			IdleTimeout:  opts.IdleTimeout,
+		},
+	}, nil
+}
+
+// Engine 返回Gin引擎，用于注册路由
+func (a *App) Engine() *gin.Engine {
+	return a.engine
+}
+
+// Health 返回应用的健康检查实例，用于注册检查项与切换就绪状态（SetStatus），
+// 未在 EngineOptions 中指定实例时与包级的 middleware.SetHealthStatus 无关
+func (a *App) Health() *middleware.HealthMonitor {
+	return a.health
+}
+
+// Logger 返回应用日志
+func (a *App) Logger() *logger.Logger {
+	return a.log
+}
+
+// ServiceContext 返回应用的服务上下文
+func (a *App) ServiceContext() *ServiceContext {
+	return a.svcCtx
+}
+
+// OnShutdown 注册关闭钩子，关闭时按注册的逆序执行（与 defer 相同，后注册的先关闭）
+func (a *App) OnShutdown(name string, hook ShutdownHook) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.hooks = append(a.hooks, shutdownHook{name: name, fn: hook})
+}
+
+// Run 启动服务上下文中的组件与HTTP服务并阻塞，直到收到关闭信号、服务上下文被取消或服务启动失败，随后执行优雅关闭
+func (a *App) Run() error {
+	// 按依赖顺序启动组件
+	if err := a.svcCtx.Start(a.svcCtx.Context()); err != nil {
+		a.log.Error("start components failed", "error", err)
+		ctx, cancel := context.WithTimeout(context.Background(), a.opts.ShutdownTimeout)
+		defer cancel()
+		return errors.Join(err, a.Shutdown(ctx))
+	}
+
+	// 启动HTTP服务前已开始关闭时直接返回关闭结果
+	a.mutex.Lock()
+	if a.stopping {
+		a.mutex.Unlock()
+		ctx, cancel := context.WithTimeout(context.Background(), a.opts.ShutdownTimeout)
+		defer cancel()
+		return a.Shutdown(ctx)
+	}
+	a.serving = true
+	a.mutex.Unlock()
+
+	serveErr := make(chan error, 1)
+	go func() {
+		a.log.Info("http server starting", "addr", a.server.Addr)
+		if err := a.server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serveErr <- err
+		}
+	}()
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, a.opts.Signals...)
+	defer signal.Stop(sigCh)
+
+	var runErr error
+	select {
+	case sig := <-sigCh:
+		a.log.Info("shutdown signal received", "signal", sig.String())
+	case err := <-serveErr:
+		a.log.Error("http server failed", "error", err)
+		runErr = err
+	case <-a.svcCtx.Context().Done():
+		a.log.Info("service context canceled")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), a.opts.ShutdownTimeout)
+	defer cancel()
+
+	return errors.Join(runErr, a.Shutdown(ctx))
+}
+
+// Shutdown 优雅关闭应用：标记为不健康、等待摘流、排空HTTP请求、执行关闭钩子、
+// 逆序停止服务上下文中的组件、取消服务上下文并关闭日志。HTTP服务未启动时（如组件启动失败）跳过摘流与关闭HTTP服务。
+// 多次调用只会执行一次，后续调用返回首次关闭的结果。
+func (a *App) Shutdown(ctx context.Context) error {
+	a.shutdownOnce.Do(func() {
+		a.shutdownErr = a.shutdown(ctx)
+	})
+	return a.shutdownErr
+}
+
+// shutdown 执行实际的关闭流程
+func (a *App) shutdown(ctx context.Context) error {
+	var errs []error
+
+	a.mutex.Lock()
+	a.stopping = true
+	serving := a.serving
+	hooks := make([]shutdownHook, len(a.hooks))
+	copy(hooks, a.hooks)
+	a.mutex.Unlock()
+
+	// 先让健康检查失败，使负载均衡停止转发新请求
+	a.health.SetStatus(false)
+	if serving {
+		if a.opts.DrainDelay > 0 {
+			a.log.Info("draining traffic", "delay", a.opts.DrainDelay.String())
+			select {
+			case <-time.After(a.opts.DrainDelay):
+			case <-ctx.Done():
+			}
+		}
+
+		// 停止接收新连接并等待处理中的请求完成
+		if err := a.server.Shutdown(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("http server shutdown: %w", err))
+		}
+	}
+
+	// 逆序执行关闭钩子
+	for i := len(hooks) - 1; i >= 0; i-- {
+		h := hooks[i]
+		if err := h.fn(ctx); err != nil {
+			a.log.Error("shutdown hook failed", "hook", h.name, "error", err)
+			errs = append(errs, fmt.Errorf("shutdown hook %s: %w", h.name, err))
+		}
+	}
+
+	// 逆序停止服务上下文中的组件
+	if err := a.svcCtx.Stop(ctx); err != nil {
+		errs = append(errs, err)
+	}
+
+	a.svcCtx.Cancel()
+
+	err := errors.Join(errs...)
+	if err != nil {
+		a.log.Error("shutdown completed with errors", "error", err)
+	} else {
+		a.log.Info("shutdown completed")
+	}
+	// 日志已注册到服务上下文时由 Stop 关闭，不重复关闭
+	if !a.svcCtx.reg.tracks(a.log) {
+		_ = a.log.Close()
+	}
+
+	return err
+}
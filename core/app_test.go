@@ -0,0 +1,184 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shrimps80/go-service-utils/logger"
+	"github.com/shrimps80/go-service-utils/middleware"
+)
+
+// newTestApp 创建监听随机端口、日志写入临时目录的应用
+func newTestApp(t *testing.T, drain time.Duration) *App {
+	t.Helper()
+	opts := DefaultAppOptions()
+	opts.Addr = "127.0.0.1:0"
+	opts.DrainDelay = drain
+	opts.ShutdownTimeout = 5 * time.Second
+	opts.Engine = &EngineOptions{
+		Mode: gin.TestMode,
+		Log:  &logger.Config{Filename: filepath.Join(t.TempDir(), "app.log"), Level: "info"},
+	}
+	app, err := NewApp(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return app
+}
+
+type failingComponent struct {
+	err error
+}
+
+func (c *failingComponent) Start(ctx context.Context) error { return c.err }
+
+func (c *failingComponent) Stop(ctx context.Context) error { return nil }
+
+func TestApp_startFailureSkipsDrain(t *testing.T) {
+	app := newTestApp(t, 10*time.Second)
+	startErr := errors.New("boom")
+	Provide(app.ServiceContext(), &failingComponent{err: startErr})
+
+	var hooked bool
+	app.OnShutdown("hook", func(ctx context.Context) error {
+		hooked = true
+		return nil
+	})
+
+	begin := time.Now()
+	err := app.Run()
+	if !errors.Is(err, startErr) {
+		t.Fatalf("got %v, want start error", err)
+	}
+	if elapsed := time.Since(begin); elapsed > time.Second {
+		t.Fatalf("shutdown after failed start took %v, drain delay not skipped", elapsed)
+	}
+	if !hooked {
+		t.Error("shutdown hook not run")
+	}
+	if app.Health().Healthy() {
+		t.Error("app still healthy after shutdown")
+	}
+	if app.ServiceContext().Context().Err() == nil {
+		t.Error("service context not canceled")
+	}
+}
+
+func TestApp_shutdownOrder(t *testing.T) {
+	app := newTestApp(t, 20*time.Millisecond)
+	var events []string
+	Provide(app.ServiceContext(), &testComponent{name: "db", events: &events})
+	app.OnShutdown("first", func(ctx context.Context) error {
+		events = append(events, "hook first")
+		return nil
+	})
+	app.OnShutdown("second", func(ctx context.Context) error {
+		events = append(events, "hook second")
+		return errors.New("hook failed")
+	})
+
+	// 服务上下文被取消时 Run 开始优雅关闭
+	time.AfterFunc(50*time.Millisecond, app.ServiceContext().Cancel)
+
+	begin := time.Now()
+	err := app.Run()
+	if err == nil || !strings.Contains(err.Error(), "shutdown hook second") {
+		t.Fatalf("got %v, want hook error", err)
+	}
+	if elapsed := time.Since(begin); elapsed < 70*time.Millisecond {
+		t.Errorf("run returned after %v, drain delay not applied", elapsed)
+	}
+
+	want := "start db,hook second,hook first,stop db"
+	if got := strings.Join(events, ","); got != want {
+		t.Fatalf("got %s want %s", got, want)
+	}
+
+	// 重复关闭返回首次关闭的结果
+	if again := app.Shutdown(context.Background()); again == nil || again.Error() != err.Error() {
+		t.Fatalf("second shutdown returned %v, want %v", again, err)
+	}
+}
+
+func TestApp_serveFailure(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	app := newTestApp(t, 0)
+	app.server.Addr = ln.Addr().String()
+
+	done := make(chan error, 1)
+	go func() { done <- app.Run() }()
+	select {
+	case err := <-done:
+		if err == nil || !strings.Contains(err.Error(), "address already in use") {
+			t.Fatalf("got %v, want listen error", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("run did not return after listen failure")
+	}
+}
+
+func TestApp_shutdownBeforeRun(t *testing.T) {
+	app := newTestApp(t, 10*time.Second)
+	begin := time.Now()
+	if err := app.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := app.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(begin); elapsed > time.Second {
+		t.Fatalf("took %v, drain delay applied without a running server", elapsed)
+	}
+}
+
+func TestApp_healthInstance(t *testing.T) {
+	probe := func(app *App) int {
+		w := httptest.NewRecorder()
+		app.Engine().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+		return w.Code
+	}
+
+	// 默认使用独立实例，包级开关不影响应用
+	app := newTestApp(t, 0)
+	middleware.SetHealthStatus(false)
+	defer middleware.SetHealthStatus(true)
+	if code := probe(app); code != http.StatusOK {
+		t.Fatalf("private instance: /readyz = %d, want 200", code)
+	}
+	app.Health().SetStatus(false)
+	if code := probe(app); code != http.StatusServiceUnavailable {
+		t.Fatalf("after app.Health().SetStatus(false): /readyz = %d, want 503", code)
+	}
+
+	// 指定默认实例后沿用包级开关
+	opts := DefaultAppOptions()
+	opts.Engine = &EngineOptions{
+		Mode:   gin.TestMode,
+		Log:    &logger.Config{Filename: filepath.Join(t.TempDir(), "app.log"), Level: "info"},
+		Health: middleware.DefaultHealth(),
+	}
+	shared, err := NewApp(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code := probe(shared); code != http.StatusServiceUnavailable {
+		t.Fatalf("default instance: /readyz = %d, want 503", code)
+	}
+	middleware.SetHealthStatus(true)
+	if code := probe(shared); code != http.StatusOK {
+		t.Fatalf("default instance after SetHealthStatus(true): /readyz = %d, want 200", code)
+	}
+}
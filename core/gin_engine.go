@@ -2,20 +2,25 @@ package core
 
 import (
 	"github.com/gin-gonic/gin"
-	"github.com/shrimps80/go-service-utils/middleware"
+	"github.com/shrimps80/go-service-utils/internal/prom"
 	"github.com/shrimps80/go-service-utils/logger"
+	"github.com/shrimps80/go-service-utils/middleware"
 )
 
 // EngineOptions 定义Gin引擎的配置选项
 type EngineOptions struct {
-	Mode string // gin模式：debug, release, test
-	Log  *logger.Config
+	Mode        string // gin模式：debug, release, test
+	Log         *logger.Config
+	Health      *middleware.HealthMonitor  // 健康检查实例，为空时使用 middleware.DefaultHealth()
+	Metrics     *middleware.MetricsOptions // 指标采集配置，为空时使用默认配置
+	MetricsPath string                     // 暴露指标的路径，为空时不挂载
 }
 
 // DefaultEngineOptions 返回默认的引擎配置
 func DefaultEngineOptions() *EngineOptions {
 	return &EngineOptions{
-		Mode: gin.ReleaseMode,
+		Mode:        gin.ReleaseMode,
+		MetricsPath: "/metrics",
 		Log: &logger.Config{
 			Filename:   "app.log",
 			MaxSize:    100,
@@ -33,24 +38,44 @@ func NewEngine(opts *EngineOptions) (*gin.Engine, error) {
 		opts = DefaultEngineOptions()
 	}
 
-	// 设置Gin模式
-	gin.SetMode(opts.Mode)
-
 	// 初始化日志
 	log, err := logger.NewLogger(opts.Log)
 	if err != nil {
 		return nil, err
 	}
 
+	return newEngine(opts, log), nil
+}
+
+// newEngine 使用已初始化的日志创建Gin引擎并注册默认中间件
+func newEngine(opts *EngineOptions, log *logger.Logger) *gin.Engine {
+	// 设置Gin模式
+	gin.SetMode(opts.Mode)
+
+	health := opts.Health
+	if health == nil {
+		health = middleware.DefaultHealth()
+	}
+
 	// 创建gin引擎
 	engine := gin.New()
 
-	// 注册默认中间件
+	metricsOpts := opts.Metrics
+	if metricsOpts == nil {
+		metricsOpts = middleware.DefaultMetricsOptions()
+	}
+
+	// 注册默认中间件；健康检查在指标收集之前处理，探针请求不计入 HTTP 指标
 	engine.Use(
-		middleware.Recovery(log),  // panic恢复
-		middleware.Metrics(),      // 指标收集
-		middleware.Health(),       // 健康检查
+		middleware.Recovery(log),                   // panic恢复
+		health.Handler(),                           // 健康检查
+		middleware.MetricsWithOptions(metricsOpts), // 指标收集
 	)
 
-	return engine, nil
-}
\ No newline at end of file
+	// 挂载指标暴露接口
+	if opts.MetricsPath != "" {
+		engine.GET(opts.MetricsPath, middleware.MetricsHandler(prom.Gatherer(metricsOpts.Registerer)))
+	}
+
+	return engine
+}
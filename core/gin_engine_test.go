@@ -0,0 +1,46 @@
+package core
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/shrimps80/go-service-utils/logger"
+	"github.com/shrimps80/go-service-utils/middleware"
+	"go.uber.org/zap"
+)
+
+func TestNewEngine_probesBypassMetrics(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	metrics := middleware.DefaultMetricsOptions()
+	metrics.Registerer = reg
+	opts := &EngineOptions{
+		Mode:        gin.TestMode,
+		Health:      middleware.NewHealth(nil),
+		Metrics:     metrics,
+		MetricsPath: "/metrics",
+	}
+	engine := newEngine(opts, &logger.Logger{Logger: zap.NewNop()})
+	engine.GET("/ping", func(c *gin.Context) { c.String(http.StatusOK, "pong") })
+
+	for _, path := range []string{"/health", "/livez", "/readyz", "/ping"} {
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: status %d", path, w.Code)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body := w.Body.String()
+	if !strings.Contains(body, `http_requests_total{method="GET",path="/ping",status="200"} 1`) {
+		t.Errorf("missing /ping series in\n%s", body)
+	}
+	if strings.Contains(body, "<unmatched>") {
+		t.Errorf("probe requests counted as unmatched:\n%s", body)
+	}
+}
@@ -0,0 +1,275 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// DefaultComponentTimeout 组件启动、停止与健康检查的默认超时时间
+const DefaultComponentTimeout = 10 * time.Second
+
+// Lifecycle 可选的组件生命周期接口。注册到 ServiceContext 的依赖实现该接口后，
+// 由 Start 按依赖顺序启动，由 Stop 按逆序停止。
+type Lifecycle interface {
+	Start(ctx context.Context) error
+	Stop(ctx context.Context) error
+}
+
+// HealthChecker 可选的组件健康检查接口
+type HealthChecker interface {
+	HealthCheck(ctx context.Context) error
+}
+
+// component 受生命周期管理的依赖
+type component struct {
+	ids     []interface{} // 引用该实例的字符串名称或 depKey
+	name    string
+	value   interface{}
+	opts    depOptions
+	started bool
+	stopped bool
+}
+
+// track 记录组件（调用方需持有 mutex）。同一 id 重复注册时移除旧组件并追加到末尾；
+// 同一实例以多个 id 注册（如同时经 Register 与 Provide）时只记录一次，避免被重复停止
+func (r *registry) track(id interface{}, name string, value interface{}, opts depOptions) {
+	r.untrack(id)
+	for _, c := range r.components {
+		if sameInstance(c.value, value) {
+			c.ids = append(c.ids, id)
+			return
+		}
+	}
+	r.components = append(r.components, &component{ids: []interface{}{id}, name: name, value: value, opts: opts})
+}
+
+// untrack 从组件中移除 id，组件不再被任何 id 引用时删除（调用方需持有 mutex）
+func (r *registry) untrack(id interface{}) {
+	for i, c := range r.components {
+		for j, cid := range c.ids {
+			if cid != id {
+				continue
+			}
+			c.ids = append(c.ids[:j], c.ids[j+1:]...)
+			if len(c.ids) == 0 {
+				r.components = append(r.components[:i], r.components[i+1:]...)
+			}
+			return
+		}
+	}
+}
+
+// tracks 判断实例是否作为组件受生命周期管理
+func (r *registry) tracks(value interface{}) bool {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	for _, c := range r.components {
+		if sameInstance(c.value, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// sameInstance 判断两个依赖是否为同一实例，只比较指针类的值，其他类型的值不视为同一实例
+func sameInstance(a, b interface{}) bool {
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	if !va.IsValid() || !vb.IsValid() || va.Type() != vb.Type() {
+		return false
+	}
+	switch va.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Chan, reflect.UnsafePointer:
+		return !va.IsNil() && va.Pointer() == vb.Pointer()
+	}
+	return false
+}
+
+// snapshot 返回当前组件列表的副本
+func (r *registry) snapshot() []*component {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	comps := make([]*component, len(r.components))
+	copy(comps, r.components)
+	return comps
+}
+
+// buildAll 按注册顺序构建所有尚未解析的工厂
+func (r *registry) buildAll(sc *ServiceContext) error {
+	r.mutex.RLock()
+	var pending []depKey
+	for key, p := range r.providers {
+		if !p.built {
+			pending = append(pending, key)
+		}
+	}
+	sort.Slice(pending, func(i, j int) bool {
+		return r.providers[pending[i]].seq < r.providers[pending[j]].seq
+	})
+	r.mutex.RUnlock()
+
+	root := sc.withChain(nil)
+	for _, key := range pending {
+		if _, err := r.resolve(root, key, []depKey{key}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Start 构建所有尚未解析的工厂，并按依赖顺序启动实现了 Lifecycle 的组件。
+// 任一组件启动失败时，按逆序停止已注册的组件并返回错误。
+func (sc *ServiceContext) Start(ctx context.Context) error {
+	if err := sc.reg.buildAll(sc); err != nil {
+		return err
+	}
+
+	for _, c := range sc.reg.snapshot() {
+		lc, ok := c.value.(Lifecycle)
+		if !ok {
+			continue
+		}
+
+		sc.reg.mutex.RLock()
+		skip := c.started || c.stopped
+		sc.reg.mutex.RUnlock()
+		if skip {
+			continue
+		}
+
+		if err := runWithTimeout(ctx, c.opts.startTimeout, lc.Start); err != nil {
+			startErr := fmt.Errorf("start component %s: %w", c.name, err)
+			return errors.Join(startErr, sc.Stop(ctx))
+		}
+
+		sc.reg.mutex.Lock()
+		c.started = true
+		sc.reg.mutex.Unlock()
+	}
+	return nil
+}
+
+// Stop 按依赖逆序停止组件，每个组件使用独立的超时且只会被停止一次，所有错误聚合后返回。
+// 实现了 Lifecycle 的组件仅在成功启动后调用其 Stop；其余组件依次回退到：
+// 底层 *sql.DB 的关闭（如 *gorm.DB）、Close() error（如 *cache.Redis、*logger.Logger）、
+// Close()+Wait()（如 pool.Pool）。
+func (sc *ServiceContext) Stop(ctx context.Context) error {
+	comps := sc.reg.snapshot()
+
+	var errs []error
+	for i := len(comps) - 1; i >= 0; i-- {
+		c := comps[i]
+
+		sc.reg.mutex.Lock()
+		if c.stopped {
+			sc.reg.mutex.Unlock()
+			continue
+		}
+		c.stopped = true
+		started := c.started
+		sc.reg.mutex.Unlock()
+
+		stop := stopFunc(c.value, started)
+		if stop == nil {
+			continue
+		}
+		if err := runWithTimeout(ctx, c.opts.stopTimeout, stop); err != nil {
+			errs = append(errs, fmt.Errorf("stop component %s: %w", c.name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// HealthCheck 对所有可检查的组件执行健康检查，返回组件名到检查结果的映射，健康的组件值为 nil。
+// 除 HealthChecker 外，也支持底层 *sql.DB 的 Ping（如 *gorm.DB）与 Ping(ctx) error（如 *cache.Redis）。
+func (sc *ServiceContext) HealthCheck(ctx context.Context) map[string]error {
+	results := make(map[string]error)
+	for _, c := range sc.reg.snapshot() {
+		check := healthFunc(c.value)
+		if check == nil {
+			continue
+		}
+		results[c.name] = runWithTimeout(ctx, c.opts.healthTimeout, check)
+	}
+	return results
+}
+
+// stopFunc 返回组件的停止函数，无法停止时返回 nil
+func stopFunc(value interface{}, started bool) func(ctx context.Context) error {
+	switch v := value.(type) {
+	case Lifecycle:
+		if !started {
+			return nil
+		}
+		return v.Stop
+	case interface{ DB() (*sql.DB, error) }:
+		return func(ctx context.Context) error {
+			sqlDB, err := v.DB()
+			if err != nil {
+				return err
+			}
+			return sqlDB.Close()
+		}
+	case io.Closer:
+		return func(ctx context.Context) error {
+			return v.Close()
+		}
+	case interface {
+		Close()
+		Wait()
+	}:
+		return func(ctx context.Context) error {
+			v.Close()
+			v.Wait()
+			return nil
+		}
+	}
+	return nil
+}
+
+// healthFunc 返回组件的健康检查函数，不支持检查时返回 nil
+func healthFunc(value interface{}) func(ctx context.Context) error {
+	switch v := value.(type) {
+	case HealthChecker:
+		return v.HealthCheck
+	case interface{ DB() (*sql.DB, error) }:
+		return func(ctx context.Context) error {
+			sqlDB, err := v.DB()
+			if err != nil {
+				return err
+			}
+			return sqlDB.PingContext(ctx)
+		}
+	case interface {
+		Ping(ctx context.Context) error
+	}:
+		return v.Ping
+	}
+	return nil
+}
+
+// runWithTimeout 在超时时间内执行 fn，超时后不再等待 fn 返回
+func runWithTimeout(ctx context.Context, timeout time.Duration, fn func(ctx context.Context) error) error {
+	if timeout <= 0 {
+		timeout = DefaultComponentTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- fn(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
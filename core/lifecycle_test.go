@@ -0,0 +1,140 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// sqlHolder 通过 DB() 暴露 *sql.DB，与 *gorm.DB 的关闭方式相同
+type sqlHolder struct{ db *sql.DB }
+
+func (h *sqlHolder) DB() (*sql.DB, error) { return h.db, nil }
+
+type testCloser struct {
+	closed int
+	err    error
+}
+
+func (c *testCloser) Close() error {
+	c.closed++
+	return c.err
+}
+
+// testPool 与 pool.Pool 相同的 Close+Wait 停止方式
+type testPool struct{ closed, waited bool }
+
+func (p *testPool) Close() { p.closed = true }
+func (p *testPool) Wait()  { p.waited = p.closed }
+
+// blockingComponent Stop 与 HealthCheck 阻塞直到 ctx 结束
+type blockingComponent struct{}
+
+func (blockingComponent) Start(ctx context.Context) error { return nil }
+
+func (blockingComponent) Stop(ctx context.Context) error {
+	<-ctx.Done()
+	return nil
+}
+
+func (blockingComponent) HealthCheck(ctx context.Context) error {
+	<-ctx.Done()
+	return nil
+}
+
+func TestServiceContext_stopFallbacks(t *testing.T) {
+	sc := NewServiceContext()
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Ping(); err != nil {
+		t.Fatal(err)
+	}
+	closer := &testCloser{}
+	p := &testPool{}
+	var events []string
+	unstarted := &testComponent{name: "unstarted", events: &events}
+
+	Provide(sc, &sqlHolder{db: db})
+	Provide(sc, closer)
+	Provide(sc, p)
+	Provide(sc, unstarted)
+
+	// 未调用 Start，Lifecycle 组件不会被停止
+	if err := sc.Stop(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Ping(); err == nil {
+		t.Error("*sql.DB not closed")
+	}
+	if closer.closed != 1 {
+		t.Errorf("io.Closer closed %d times, want 1", closer.closed)
+	}
+	if !p.closed || !p.waited {
+		t.Errorf("pool closed = %v, waited after close = %v", p.closed, p.waited)
+	}
+	if len(events) != 0 {
+		t.Errorf("unstarted component got %v", events)
+	}
+}
+
+func TestServiceContext_stopTimeoutAndErrors(t *testing.T) {
+	sc := NewServiceContext()
+	closeErr := errors.New("close failed")
+	first := &testCloser{}
+	Provide(sc, first, Named("first"))
+	Provide(sc, &testCloser{err: closeErr}, Named("failing"))
+	Provide(sc, blockingComponent{}, WithStopTimeout(20*time.Millisecond))
+	if err := sc.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	err := sc.Stop(context.Background())
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("stop took %v, per-component timeout not applied", elapsed)
+	}
+	if !errors.Is(err, closeErr) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got %v, want both errors aggregated", err)
+	}
+	// 前面的组件出错或超时不影响后续组件的停止
+	if first.closed != 1 {
+		t.Errorf("first closed %d times, want 1", first.closed)
+	}
+}
+
+func TestServiceContext_healthTimeout(t *testing.T) {
+	sc := NewServiceContext()
+	Provide(sc, blockingComponent{}, WithStartTimeout(time.Hour), WithHealthTimeout(20*time.Millisecond))
+
+	start := time.Now()
+	results := sc.HealthCheck(context.Background())
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("health check took %v, health timeout not applied", elapsed)
+	}
+	if err := results["core.blockingComponent"]; !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got %v, want DeadlineExceeded", results)
+	}
+}
+
+func TestServiceContext_trackDedupe(t *testing.T) {
+	sc := NewServiceContext()
+	closer := &testCloser{}
+	sc.Register("closer", closer)
+	Provide(sc, closer)
+
+	// 同一实例的其中一个注册被替换后，实例仍受管理
+	sc.Register("closer", &testCloser{})
+
+	if err := sc.Stop(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if closer.closed != 1 {
+		t.Fatalf("closed %d times, want 1", closer.closed)
+	}
+}
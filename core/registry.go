@@ -0,0 +1,239 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// 依赖解析错误定义
+var (
+	ErrDependencyNotFound = errors.New("dependency not found")
+	ErrDependencyCycle    = errors.New("dependency cycle detected")
+	// ErrReentrantResolve 依赖正在构建时再次被请求，如工厂经外层（而非传入的）ServiceContext 间接解析自身
+	ErrReentrantResolve = errors.New("dependency is already being built, resolve through the ServiceContext passed to the factory")
+)
+
+// ResolveError 依赖解析失败的错误，Chain 为从最外层依赖到出错依赖的解析链
+type ResolveError struct {
+	Chain []string
+	Err   error
+}
+
+// Error 实现 error 接口
+func (e *ResolveError) Error() string {
+	return fmt.Sprintf("resolve %s: %v", strings.Join(e.Chain, " -> "), e.Err)
+}
+
+// Unwrap 返回底层错误，便于 errors.Is 判断
+func (e *ResolveError) Unwrap() error {
+	return e.Err
+}
+
+// depKey 类型化依赖的键：类型加可选名称
+type depKey struct {
+	typ  reflect.Type
+	name string
+}
+
+// String 返回依赖键的可读形式，如 *cache.Redis 或 *gorm.DB#replica
+func (k depKey) String() string {
+	if k.name == "" {
+		return k.typ.String()
+	}
+	return k.typ.String() + "#" + k.name
+}
+
+// provider 依赖提供者：已构建的值或惰性单例工厂
+type provider struct {
+	seq      int // 注册顺序，Start 时按此顺序构建尚未解析的工厂
+	factory  func(sc *ServiceContext) (interface{}, error)
+	value    interface{}
+	built    bool
+	building bool // 工厂正在构建，期间再次请求返回 ErrReentrantResolve
+	opts     depOptions
+}
+
+// DepOption 类型化依赖的选项
+type DepOption func(*depOptions)
+
+type depOptions struct {
+	name          string
+	startTimeout  time.Duration
+	stopTimeout   time.Duration
+	healthTimeout time.Duration
+}
+
+// Named 为依赖指定名称，用于同一类型注册多个实例
+func Named(name string) DepOption {
+	return func(o *depOptions) {
+		o.name = name
+	}
+}
+
+// WithStartTimeout 设置组件 Start 的超时时间，默认为 DefaultComponentTimeout
+func WithStartTimeout(timeout time.Duration) DepOption {
+	return func(o *depOptions) {
+		o.startTimeout = timeout
+	}
+}
+
+// WithStopTimeout 设置组件 Stop 的超时时间，默认为 DefaultComponentTimeout
+func WithStopTimeout(timeout time.Duration) DepOption {
+	return func(o *depOptions) {
+		o.stopTimeout = timeout
+	}
+}
+
+// WithHealthTimeout 设置组件健康检查的超时时间，默认为 DefaultComponentTimeout
+func WithHealthTimeout(timeout time.Duration) DepOption {
+	return func(o *depOptions) {
+		o.healthTimeout = timeout
+	}
+}
+
+// keyOf 计算类型 T 与选项对应的依赖键
+func keyOf[T any](opts []DepOption) (depKey, depOptions) {
+	var o depOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return depKey{typ: reflect.TypeOf((*T)(nil)).Elem(), name: o.name}, o
+}
+
+// Provide 以类型 T（及可选名称）注册一个已构建的依赖，重复注册会覆盖
+func Provide[T any](sc *ServiceContext, value T, opts ...DepOption) {
+	key, o := keyOf[T](opts)
+	sc.reg.mutex.Lock()
+	defer sc.reg.mutex.Unlock()
+	sc.reg.seq++
+	sc.reg.providers[key] = &provider{seq: sc.reg.seq, value: value, built: true, opts: o}
+	sc.reg.track(key, key.String(), value, o)
+}
+
+// ProvideFactory 以类型 T（及可选名称）注册一个惰性单例工厂，首次 Resolve 时构建且只构建一次。
+// 工厂收到的 ServiceContext 用于解析其自身的依赖，以便检测缺失与循环依赖。
+func ProvideFactory[T any](sc *ServiceContext, factory func(sc *ServiceContext) (T, error), opts ...DepOption) {
+	key, o := keyOf[T](opts)
+	sc.reg.mutex.Lock()
+	defer sc.reg.mutex.Unlock()
+	sc.reg.seq++
+	sc.reg.providers[key] = &provider{
+		seq: sc.reg.seq,
+		factory: func(sc *ServiceContext) (interface{}, error) {
+			return factory(sc)
+		},
+		opts: o,
+	}
+}
+
+// Resolve 按类型 T（及可选名称）获取依赖，必要时调用工厂构建
+func Resolve[T any](sc *ServiceContext, opts ...DepOption) (T, error) {
+	var zero T
+	key, _ := keyOf[T](opts)
+
+	chain := make([]depKey, len(sc.chain), len(sc.chain)+1)
+	copy(chain, sc.chain)
+	chain = append(chain, key)
+
+	// 检测循环依赖
+	for _, k := range sc.chain {
+		if k == key {
+			return zero, &ResolveError{Chain: chainNames(chain), Err: ErrDependencyCycle}
+		}
+	}
+
+	p, err := sc.reg.resolve(sc, key, chain)
+	if err != nil {
+		return zero, err
+	}
+	if p == nil {
+		return zero, nil
+	}
+
+	v, ok := p.(T)
+	if !ok {
+		return zero, &ResolveError{Chain: chainNames(chain), Err: fmt.Errorf("unexpected type %T", p)}
+	}
+	return v, nil
+}
+
+// MustResolve 按类型 T（及可选名称）获取依赖，失败时panic
+func MustResolve[T any](sc *ServiceContext, opts ...DepOption) T {
+	v, err := Resolve[T](sc, opts...)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// resolve 返回依赖值，未构建时调用工厂构建。
+// 构建状态按依赖键记录，不同依赖的构建互不阻塞；依赖正在构建时再次请求（重入或并发的首次解析）返回 ErrReentrantResolve
+func (r *registry) resolve(sc *ServiceContext, key depKey, chain []depKey) (interface{}, error) {
+	r.mutex.RLock()
+	p, ok := r.providers[key]
+	if ok && p.built {
+		value := p.value
+		r.mutex.RUnlock()
+		return value, nil
+	}
+	r.mutex.RUnlock()
+
+	if !ok {
+		return nil, &ResolveError{Chain: chainNames(chain), Err: ErrDependencyNotFound}
+	}
+
+	r.mutex.Lock()
+	if p.built {
+		value := p.value
+		r.mutex.Unlock()
+		return value, nil
+	}
+	if p.building {
+		r.mutex.Unlock()
+		return nil, &ResolveError{Chain: chainNames(chain), Err: ErrReentrantResolve}
+	}
+	p.building = true
+	r.mutex.Unlock()
+
+	value, err := r.build(sc.withChain(chain), key, p)
+	if err != nil {
+		var re *ResolveError
+		if errors.As(err, &re) {
+			return nil, err
+		}
+		return nil, &ResolveError{Chain: chainNames(chain), Err: err}
+	}
+	return value, nil
+}
+
+// build 调用工厂并记录结果；工厂出错或 panic 时清除构建标记，之后可重新构建
+func (r *registry) build(sc *ServiceContext, key depKey, p *provider) (value interface{}, err error) {
+	finished := false
+	defer func() {
+		r.mutex.Lock()
+		defer r.mutex.Unlock()
+		p.building = false
+		if finished && err == nil {
+			// 依赖在其使用方之前构建完成，按完成顺序记录即为依赖顺序
+			p.value = value
+			p.built = true
+			r.track(key, key.String(), value, p.opts)
+		}
+	}()
+
+	value, err = p.factory(sc)
+	finished = true
+	return value, err
+}
+
+// chainNames 将解析链转换为可读名称
+func chainNames(chain []depKey) []string {
+	names := make([]string, len(chain))
+	for i, k := range chain {
+		names[i] = k.String()
+	}
+	return names
+}
@@ -0,0 +1,221 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testRepo struct{ dsn string }
+
+type testService struct{ repo *testRepo }
+
+func TestResolve_factoryIsSingleton(t *testing.T) {
+	sc := NewServiceContext()
+	calls := 0
+	Provide(sc, &testRepo{dsn: "primary"})
+	ProvideFactory(sc, func(sc *ServiceContext) (*testService, error) {
+		calls++
+		return &testService{repo: MustResolve[*testRepo](sc)}, nil
+	})
+
+	a := MustResolve[*testService](sc)
+	b := MustResolve[*testService](sc)
+	if a != b || calls != 1 {
+		t.Fatalf("expected singleton, calls=%d", calls)
+	}
+	if a.repo.dsn != "primary" {
+		t.Fatalf("got %q", a.repo.dsn)
+	}
+}
+
+func TestResolve_named(t *testing.T) {
+	sc := NewServiceContext()
+	Provide(sc, &testRepo{dsn: "primary"})
+	Provide(sc, &testRepo{dsn: "replica"}, Named("replica"))
+
+	if got := MustResolve[*testRepo](sc, Named("replica")).dsn; got != "replica" {
+		t.Fatalf("got %q", got)
+	}
+	if got := MustResolve[*testRepo](sc).dsn; got != "primary" {
+		t.Fatalf("got %q", got)
+	}
+}
+
+func TestResolve_notFound(t *testing.T) {
+	sc := NewServiceContext()
+	ProvideFactory(sc, func(sc *ServiceContext) (*testService, error) {
+		repo, err := Resolve[*testRepo](sc)
+		if err != nil {
+			return nil, err
+		}
+		return &testService{repo: repo}, nil
+	})
+
+	_, err := Resolve[*testService](sc)
+	if !errors.Is(err, ErrDependencyNotFound) {
+		t.Fatalf("got %v", err)
+	}
+	if !strings.Contains(err.Error(), "*core.testService -> *core.testRepo") {
+		t.Fatalf("missing chain: %v", err)
+	}
+}
+
+func TestResolve_cycle(t *testing.T) {
+	sc := NewServiceContext()
+	ProvideFactory(sc, func(sc *ServiceContext) (*testRepo, error) {
+		_, err := Resolve[*testService](sc)
+		return &testRepo{}, err
+	})
+	ProvideFactory(sc, func(sc *ServiceContext) (*testService, error) {
+		repo, err := Resolve[*testRepo](sc)
+		return &testService{repo: repo}, err
+	})
+
+	_, err := Resolve[*testService](sc)
+	if !errors.Is(err, ErrDependencyCycle) {
+		t.Fatalf("got %v", err)
+	}
+	var re *ResolveError
+	if !errors.As(err, &re) || len(re.Chain) != 3 {
+		t.Fatalf("unexpected chain: %v", err)
+	}
+}
+
+type testComponent struct {
+	name   string
+	events *[]string
+}
+
+func (c *testComponent) Start(ctx context.Context) error {
+	*c.events = append(*c.events, "start "+c.name)
+	return nil
+}
+
+func (c *testComponent) Stop(ctx context.Context) error {
+	*c.events = append(*c.events, "stop "+c.name)
+	return nil
+}
+
+func TestServiceContext_lifecycleOrder(t *testing.T) {
+	sc := NewServiceContext()
+	var events []string
+
+	// 注册顺序与依赖顺序相反：api 依赖 db，启动时 db 必须先启动
+	ProvideFactory(sc, func(sc *ServiceContext) (*testComponent, error) {
+		MustResolve[*testComponent](sc, Named("db"))
+		return &testComponent{name: "api", events: &events}, nil
+	}, Named("api"))
+	ProvideFactory(sc, func(sc *ServiceContext) (*testComponent, error) {
+		return &testComponent{name: "db", events: &events}, nil
+	}, Named("db"))
+
+	if err := sc.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := sc.Stop(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	// 重复停止不会再次调用 Stop
+	if err := sc.Stop(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "start db,start api,stop api,stop db"
+	if got := strings.Join(events, ","); got != want {
+		t.Fatalf("got %s want %s", got, want)
+	}
+}
+
+func TestResolve_reentrantOuterContext(t *testing.T) {
+	sc := NewServiceContext()
+	outer := sc
+	ProvideFactory(sc, func(_ *ServiceContext) (*testRepo, error) {
+		// 经外层 ServiceContext 构建其他依赖不会阻塞
+		svc, err := Resolve[*testService](outer)
+		if err != nil {
+			return nil, err
+		}
+		return svc.repo, nil
+	})
+	ProvideFactory(sc, func(_ *ServiceContext) (*testService, error) {
+		// 从另一个协程经外层 ServiceContext 请求正在构建的 *testRepo
+		errCh := make(chan error, 1)
+		go func() {
+			_, err := Resolve[*testRepo](outer)
+			errCh <- err
+		}()
+		return &testService{}, <-errCh
+	})
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := Resolve[*testRepo](sc)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if !errors.Is(err, ErrReentrantResolve) {
+			t.Fatalf("got %v, want ErrReentrantResolve", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("resolve deadlocked")
+	}
+
+	// 构建失败后清除构建标记，可以重新构建
+	ProvideFactory(sc, func(_ *ServiceContext) (*testService, error) {
+		return &testService{}, nil
+	})
+	if _, err := Resolve[*testRepo](sc); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestResolve_independentBuilds(t *testing.T) {
+	sc := NewServiceContext()
+	release := make(chan struct{})
+	ProvideFactory(sc, func(sc *ServiceContext) (*testRepo, error) {
+		<-release
+		return &testRepo{dsn: "slow"}, nil
+	})
+	ProvideFactory(sc, func(sc *ServiceContext) (*testService, error) {
+		return &testService{}, nil
+	})
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := Resolve[*testRepo](sc)
+		done <- err
+	}()
+
+	// 慢工厂构建期间，其他依赖的构建不被阻塞
+	if _, err := Resolve[*testService](sc); err != nil {
+		t.Fatal(err)
+	}
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestResolve_factoryPanicClearsBuilding(t *testing.T) {
+	sc := NewServiceContext()
+	fail := true
+	ProvideFactory(sc, func(sc *ServiceContext) (*testRepo, error) {
+		if fail {
+			panic("boom")
+		}
+		return &testRepo{}, nil
+	})
+
+	func() {
+		defer func() { _ = recover() }()
+		_, _ = Resolve[*testRepo](sc)
+	}()
+	fail = false
+	if _, err := Resolve[*testRepo](sc); err != nil {
+		t.Fatalf("got %v after panic", err)
+	}
+}
@@ -9,8 +9,17 @@ import (
 type ServiceContext struct {
 	ctx    context.Context
 	cancel context.CancelFunc
-	mutex  sync.RWMutex
-	deps   map[string]interface{}
+	reg    *registry
+	chain  []depKey // 当前解析链，仅在工厂函数收到的解析视图中非空
+}
+
+// registry 依赖存储，由服务上下文及其解析视图共享
+type registry struct {
+	mutex      sync.RWMutex
+	deps       map[string]interface{}
+	providers  map[depKey]*provider
+	seq        int
+	components []*component // 按依赖顺序排列的组件，用于生命周期管理
 }
 
 // NewServiceContext 创建一个新的服务上下文
@@ -19,7 +28,10 @@ func NewServiceContext() *ServiceContext {
 	return &ServiceContext{
 		ctx:    ctx,
 		cancel: cancel,
-		deps:   make(map[string]interface{}),
+		reg: &registry{
+			deps:      make(map[string]interface{}),
+			providers: make(map[depKey]*provider),
+		},
 	}
 }
 
@@ -35,16 +47,17 @@ func (sc *ServiceContext) Cancel() {
 
 // Register 注册一个依赖
 func (sc *ServiceContext) Register(name string, dependency interface{}) {
-	sc.mutex.Lock()
-	defer sc.mutex.Unlock()
-	sc.deps[name] = dependency
+	sc.reg.mutex.Lock()
+	defer sc.reg.mutex.Unlock()
+	sc.reg.deps[name] = dependency
+	sc.reg.track(name, name, dependency, depOptions{})
 }
 
 // Get 获取一个依赖
 func (sc *ServiceContext) Get(name string) interface{} {
-	sc.mutex.RLock()
-	defer sc.mutex.RUnlock()
-	return sc.deps[name]
+	sc.reg.mutex.RLock()
+	defer sc.reg.mutex.RUnlock()
+	return sc.reg.deps[name]
 }
 
 // MustGet 获取一个依赖，如果不存在则panic
@@ -53,4 +66,14 @@ func (sc *ServiceContext) MustGet(name string) interface{} {
 		return dep
 	}
 	panic("dependency not found: " + name)
-}
\ No newline at end of file
+}
+
+// withChain 返回共享同一依赖存储、携带指定解析链的视图
+func (sc *ServiceContext) withChain(chain []depKey) *ServiceContext {
+	return &ServiceContext{
+		ctx:    sc.ctx,
+		cancel: sc.cancel,
+		reg:    sc.reg,
+		chain:  chain,
+	}
+}
@@ -0,0 +1,217 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/shrimps80/go-service-utils/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// SortKey 游标分页的排序键，最后一个键必须唯一（通常为主键），且各键取值不能为 NULL
+type SortKey struct {
+	Column string
+	Desc   bool
+}
+
+// CursorQuery 游标分页参数，可直接通过 ShouldBindQuery 绑定
+type CursorQuery struct {
+	Cursor   string `form:"cursor" json:"cursor"`
+	PageSize int    `form:"pageSize" json:"pageSize"`
+}
+
+// CursorPage 游标分页结果，JSON 字段与 utils.CursorPage 一致
+type CursorPage[T any] struct {
+	List       []T    `json:"list"`
+	PageSize   int    `json:"pageSize"`
+	NextCursor string `json:"nextCursor"`
+	PrevCursor string `json:"prevCursor"`
+	HasMore    bool   `json:"hasMore"`
+}
+
+// ToCursorPage 转换为 utils.CursorPage，便于与 utils.Success 配合使用
+func (p *CursorPage[T]) ToCursorPage() *utils.CursorPage {
+	return utils.NewCursorPage(p.List, p.PageSize, p.NextCursor, p.PrevCursor, p.HasMore)
+}
+
+// KeysetScope 返回按排序键定位到游标之后（backward 为 true 时为之前）的查询条件与排序，
+// 条件展开为 (a > ?) OR (a = ? AND b > ?) ... 的形式，以支持各键不同的排序方向
+func KeysetScope(keys []SortKey, values []any, backward bool) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if len(values) > 0 {
+			if len(values) != len(keys) {
+				_ = db.AddError(fmt.Errorf("%w: expected %d values, got %d", utils.ErrInvalidCursor, len(keys), len(values)))
+				return db
+			}
+
+			var ors []clause.Expression
+			for i, key := range keys {
+				var ands []clause.Expression
+				for j := 0; j < i; j++ {
+					ands = append(ands, clause.Eq{Column: keyColumn(keys[j]), Value: values[j]})
+				}
+
+				// 向后翻页时比较方向取反
+				if key.Desc != backward {
+					ands = append(ands, clause.Lt{Column: keyColumn(key), Value: values[i]})
+				} else {
+					ands = append(ands, clause.Gt{Column: keyColumn(key), Value: values[i]})
+				}
+				ors = append(ors, clause.And(ands...))
+			}
+			db = db.Where(clause.Or(ors...))
+		}
+
+		orders := make([]clause.OrderByColumn, len(keys))
+		for i, key := range keys {
+			orders[i] = clause.OrderByColumn{Column: keyColumn(key), Desc: key.Desc != backward}
+		}
+		return db.Order(clause.OrderBy{Columns: orders})
+	}
+}
+
+// keyColumn 返回排序键对应的列
+func keyColumn(key SortKey) clause.Column {
+	return clause.Column{Table: clause.CurrentTable, Name: key.Column}
+}
+
+// FindCursorPage 游标分页查询：keys 为排序键，codec 用于签发与校验游标，filter 与 scopes 同 FindPage
+func (r *Repository[T]) FindCursorPage(ctx context.Context, codec *utils.CursorCodec, q CursorQuery, keys []SortKey, filter any, scopes ...func(*gorm.DB) *gorm.DB) (*CursorPage[T], error) {
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("%w: no sort keys", ErrInvalidSort)
+	}
+	_, pageSize := r.normalizePage(PageQuery{PageSize: q.PageSize})
+
+	fields, err := r.keyFields(keys)
+	if err != nil {
+		return nil, err
+	}
+
+	var cur utils.Cursor
+	if q.Cursor != "" {
+		decoded, err := codec.Decode(q.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		if cur, err = restoreCursor(decoded, fields); err != nil {
+			return nil, err
+		}
+	}
+
+	conds, err := buildFilters(filter)
+	if err != nil {
+		return nil, err
+	}
+	db, err := r.query(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(conds) > 0 {
+		db = db.Clauses(clause.Where{Exprs: conds})
+	}
+
+	var list []T
+	err = db.Scopes(scopes...).
+		Scopes(KeysetScope(keys, cur.Values, cur.Backward)).
+		Limit(pageSize + 1).
+		Find(&list).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// 多取一条用于判断该方向是否还有数据
+	extra := len(list) > pageSize
+	if extra {
+		list = list[:pageSize]
+	}
+	if cur.Backward {
+		for i, j := 0, len(list)-1; i < j; i, j = i+1, j-1 {
+			list[i], list[j] = list[j], list[i]
+		}
+	}
+
+	page := &CursorPage[T]{List: list, PageSize: pageSize}
+	if page.List == nil {
+		page.List = []T{}
+	}
+	if len(list) == 0 {
+		return page, nil
+	}
+
+	// 向后翻页时后面必然还有数据；向前翻页时只要不是首页，前面就还有数据
+	hasNext, hasPrev := extra, q.Cursor != ""
+	if cur.Backward {
+		hasNext, hasPrev = true, extra
+	}
+	if hasNext {
+		if page.NextCursor, err = encodeCursor(ctx, codec, fields, &list[len(list)-1], false); err != nil {
+			return nil, err
+		}
+	}
+	if hasPrev {
+		if page.PrevCursor, err = encodeCursor(ctx, codec, fields, &list[0], true); err != nil {
+			return nil, err
+		}
+	}
+	page.HasMore = hasNext
+	return page, nil
+}
+
+// keyFields 返回排序键对应的模型字段，不存在的列返回 ErrInvalidSort
+func (r *Repository[T]) keyFields(keys []SortKey) ([]*schema.Field, error) {
+	stmt := &gorm.Statement{DB: r.db}
+	if err := stmt.Parse(new(T)); err != nil {
+		return nil, err
+	}
+
+	fields := make([]*schema.Field, len(keys))
+	for i, key := range keys {
+		field := stmt.Schema.LookUpField(key.Column)
+		if field == nil || field.DBName == "" {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidSort, key.Column)
+		}
+		fields[i] = field
+	}
+	return fields, nil
+}
+
+// encodeCursor 以 entity 的排序键取值签发游标
+func encodeCursor[T any](ctx context.Context, codec *utils.CursorCodec, fields []*schema.Field, entity *T, backward bool) (string, error) {
+	rv := reflect.ValueOf(entity).Elem()
+	values := make([]any, len(fields))
+	for i, field := range fields {
+		values[i], _ = field.ValueOf(ctx, rv)
+	}
+	return codec.Encode(utils.Cursor{Values: values, Backward: backward})
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// restoreCursor 按字段类型还原游标取值：时间字段从 RFC3339 字符串解析
+func restoreCursor(cur *utils.Cursor, fields []*schema.Field) (utils.Cursor, error) {
+	if len(cur.Values) != len(fields) {
+		return utils.Cursor{}, utils.ErrInvalidCursor
+	}
+
+	values := make([]any, len(cur.Values))
+	for i, v := range cur.Values {
+		values[i] = v
+
+		typ := fields[i].FieldType
+		if typ.Kind() == reflect.Ptr {
+			typ = typ.Elem()
+		}
+		if s, ok := v.(string); ok && typ == timeType {
+			t, err := time.Parse(time.RFC3339Nano, s)
+			if err != nil {
+				return utils.Cursor{}, utils.ErrInvalidCursor
+			}
+			values[i] = t
+		}
+	}
+	return utils.Cursor{Values: values, Backward: cur.Backward}, nil
+}
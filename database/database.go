@@ -1,79 +1,181 @@
 package database
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
+	"log"
+	"os"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/shrimps80/go-service-utils/logger"
 	"gorm.io/driver/mysql"
 	"gorm.io/driver/postgres"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
+	gormlogger "gorm.io/gorm/logger"
 )
 
 // Config 数据库配置
 type Config struct {
-	Type         string        // 数据库类型：mysql, postgres, sqlite
-	DSN          string        // 数据源名称
-	MaxIdleConns int           // 最大空闲连接数
-	MaxOpenConns int           // 最大打开连接数
-	MaxLifetime  time.Duration // 连接最大生命周期
-	Debug        bool          // 是否开启调试模式
+	Type          string                // 数据库类型：mysql, postgres, sqlite
+	DSN           string                // 数据源名称
+	MaxIdleConns  int                   // 最大空闲连接数
+	MaxOpenConns  int                   // 最大打开连接数
+	MaxLifetime   time.Duration         // 连接最大生命周期
+	MaxIdleTime   time.Duration         // 连接最大空闲时间，0 表示不限制
+	Debug         bool                  // 是否开启调试模式
+	Name          string                // 实例名称，用作指标标签与 span 属性，默认取 Type
+	Logger        *logger.Logger        // 不为空时 GORM 日志输出到该日志并附带 trace_id
+	LogLevel      string                // GORM 日志级别：silent, error, warn, info；为空时调试模式为 info，设置了 Logger 时为 warn，否则为 silent
+	SlowThreshold time.Duration         // 慢查询阈值，超过时以 warn 级别记录，为 0 时使用默认值 200ms
+	Tracing       bool                  // 是否为每条语句创建 OpenTelemetry span
+	Metrics       prometheus.Registerer // 不为空时注册查询耗时与连接池统计指标
+
+	Replicas              []string      // 只读副本的数据源名称，事务外的读请求路由到副本
+	ReplicaPolicy         string        // 副本负载均衡策略：random（默认）, round_robin
+	ReplicaHealthInterval time.Duration // 副本健康检查间隔，探测失败的副本被摘除，为 0 时不检查
+
+	Retry RetryConfig // 启动时连接失败的重试策略，默认不重试
 }
 
 // DefaultConfig 返回默认数据库配置
 func DefaultConfig() *Config {
 	return &Config{
-		Type:         "mysql",
-		MaxIdleConns: 10,
-		MaxOpenConns: 100,
-		MaxLifetime:  time.Hour,
-		Debug:        false,
+		Type:          "mysql",
+		MaxIdleConns:  10,
+		MaxOpenConns:  100,
+		MaxLifetime:   time.Hour,
+		Debug:         false,
+		SlowThreshold: 200 * time.Millisecond,
+
+		ReplicaPolicy:         ReplicaPolicyRandom,
+		ReplicaHealthInterval: 10 * time.Second,
 	}
 }
 
 // New 创建数据库连接
 func New(cfg *Config) (*gorm.DB, error) {
+	return NewWithContext(context.Background(), cfg)
+}
+
+// NewWithContext 创建数据库连接，按 cfg.Retry 重试直到连接成功、达到重试上限或 ctx 被取消
+func NewWithContext(ctx context.Context, cfg *Config) (*gorm.DB, error) {
+	// 复制配置，填充默认值时不修改调用方的 cfg
 	if cfg == nil {
 		cfg = DefaultConfig()
+	} else {
+		c := *cfg
+		cfg = &c
 	}
-
-	// 配置日志级别
-	logLevel := logger.Silent
-	if cfg.Debug {
-		logLevel = logger.Info
+	if cfg.Name == "" {
+		cfg.Name = cfg.Type
+	}
+	if cfg.SlowThreshold <= 0 {
+		cfg.SlowThreshold = DefaultConfig().SlowThreshold
 	}
 
-	// 根据数据库类型选择驱动
-	var dialector gorm.Dialector
-	switch cfg.Type {
-	case "mysql":
-		dialector = mysql.Open(cfg.DSN)
-	case "postgres":
-		dialector = postgres.Open(cfg.DSN)
-	case "sqlite":
-		dialector = sqlite.Open(cfg.DSN)
-	default:
-		return nil, fmt.Errorf("unsupported database type: %s", cfg.Type)
+	// 配置日志
+	gormLog, err := newLogger(cfg)
+	if err != nil {
+		return nil, err
 	}
 
 	// 创建数据库连接
-	db, err := gorm.Open(dialector, &gorm.Config{
-		Logger: logger.Default.LogMode(logLevel),
+	db, err := openWithRetry(ctx, cfg, cfg.DSN, &gorm.Config{
+		Logger: gormLog,
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	// 配置连接池
 	sqlDB, err := db.DB()
 	if err != nil {
 		return nil, err
 	}
 
+	// 安装追踪与指标插件
+	if plugin := newInstrumentPlugin(cfg); plugin != nil {
+		if err := db.Use(plugin); err != nil {
+			_ = sqlDB.Close()
+			return nil, err
+		}
+	}
+	if cfg.Metrics != nil {
+		if err := registerDBStats(db, cfg); err != nil {
+			_ = sqlDB.Close()
+			return nil, err
+		}
+	}
+
+	// 配置连接池
+	configurePool(sqlDB, cfg)
+
+	// 配置读写分离
+	if len(cfg.Replicas) > 0 {
+		r, err := newResolver(ctx, cfg)
+		if err != nil {
+			_ = sqlDB.Close()
+			return nil, err
+		}
+		if err := db.Use(r); err != nil {
+			_ = r.close()
+			_ = sqlDB.Close()
+			return nil, err
+		}
+	}
+
+	return db, nil
+}
+
+// openDialector 根据数据库类型创建驱动
+func openDialector(typ, dsn string) (gorm.Dialector, error) {
+	switch typ {
+	case "mysql":
+		return mysql.Open(dsn), nil
+	case "postgres":
+		return postgres.Open(dsn), nil
+	case "sqlite":
+		return sqlite.Open(dsn), nil
+	default:
+		return nil, fmt.Errorf("unsupported database type: %s", typ)
+	}
+}
+
+// configurePool 配置连接池参数
+func configurePool(sqlDB *sql.DB, cfg *Config) {
 	sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
 	sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
 	sqlDB.SetConnMaxLifetime(cfg.MaxLifetime)
+	sqlDB.SetConnMaxIdleTime(cfg.MaxIdleTime)
+}
 
-	return db, nil
-}
\ No newline at end of file
+// newLogger 根据配置创建 GORM 日志
+func newLogger(cfg *Config) (gormlogger.Interface, error) {
+	// 配置日志级别
+	logLevel := gormlogger.Silent
+	switch {
+	case cfg.LogLevel != "":
+		level, err := parseLogLevel(cfg.LogLevel)
+		if err != nil {
+			return nil, err
+		}
+		logLevel = level
+	case cfg.Debug:
+		logLevel = gormlogger.Info
+	case cfg.Logger != nil:
+		logLevel = gormlogger.Warn
+	}
+
+	if cfg.Logger != nil {
+		return newGormLogger(cfg.Logger, logLevel, cfg.SlowThreshold), nil
+	}
+
+	return gormlogger.New(log.New(os.Stdout, "\r\n", log.LstdFlags), gormlogger.Config{
+		SlowThreshold:             cfg.SlowThreshold,
+		LogLevel:                  logLevel,
+		IgnoreRecordNotFoundError: false,
+		Colorful:                  true,
+	}), nil
+}
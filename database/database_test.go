@@ -0,0 +1,179 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/shrimps80/go-service-utils/internal/tracetest"
+	"github.com/shrimps80/go-service-utils/logger"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+func TestNewDoesNotMutateConfig(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Type = "sqlite"
+	cfg.DSN = filepath.Join(t.TempDir(), "test.db")
+	db, err := New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer Close(db)
+
+	if cfg.Name != "" {
+		t.Fatalf("cfg.Name = %q, want it left empty", cfg.Name)
+	}
+}
+
+func TestNewDefaultsSlowThreshold(t *testing.T) {
+	db, err := New(&Config{
+		Type:   "sqlite",
+		DSN:    filepath.Join(t.TempDir(), "test.db"),
+		Logger: &logger.Logger{Logger: zap.NewNop()},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer Close(db)
+
+	l, ok := db.Config.Logger.(*gormLogger)
+	if !ok {
+		t.Fatalf("logger = %T, want *gormLogger", db.Config.Logger)
+	}
+	if want := DefaultConfig().SlowThreshold; l.slowThreshold != want {
+		t.Fatalf("slowThreshold = %v, want %v", l.slowThreshold, want)
+	}
+}
+
+func TestGormLogger(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	l := newGormLogger(&logger.Logger{Logger: zap.New(core)}, gormlogger.Warn, 10*time.Millisecond)
+	sql := func() (string, int64) { return "SELECT 1", 1 }
+	ctx := context.WithValue(context.Background(), "trace_id", "abc")
+
+	l.Trace(ctx, time.Now(), sql, errors.New("boom"))
+	l.Trace(ctx, time.Now(), sql, gorm.ErrRecordNotFound)
+	l.Trace(ctx, time.Now().Add(-time.Second), sql, nil)
+	l.Trace(ctx, time.Now(), sql, nil) // Warn 级别下不记录普通语句
+
+	entries := logs.TakeAll()
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2: %+v", len(entries), entries)
+	}
+	if e := entries[0]; e.Level != zapcore.ErrorLevel || e.ContextMap()["sql"] != "SELECT 1" || e.ContextMap()["trace_id"] != "abc" {
+		t.Errorf("unexpected error entry: %+v", e)
+	}
+	if e := entries[1]; e.Message != "gorm slow query" || e.LoggerName != "gorm" {
+		t.Errorf("unexpected slow entry: %+v", e)
+	}
+
+	// span 中的 trace_id 优先于上下文中的值
+	traceID := trace.TraceID{1, 2, 3}
+	spanCtx := trace.ContextWithSpanContext(ctx, trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID}))
+	l.LogMode(gormlogger.Info).Trace(spanCtx, time.Now(), sql, nil)
+	entries = logs.TakeAll()
+	if len(entries) != 1 || entries[0].ContextMap()["trace_id"] != traceID.String() {
+		t.Fatalf("unexpected info entries: %+v", entries)
+	}
+
+	l.LogMode(gormlogger.Silent).Trace(ctx, time.Now(), sql, errors.New("boom"))
+	if n := logs.Len(); n != 0 {
+		t.Fatalf("silent logger wrote %d entries", n)
+	}
+}
+
+func TestInstrumentPlugin(t *testing.T) {
+	tp := &tracetest.Provider{}
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(tp)
+	defer otel.SetTracerProvider(prev)
+
+	reg := prometheus.NewRegistry()
+	cfg := DefaultConfig()
+	cfg.Type = "sqlite"
+	cfg.DSN = filepath.Join(t.TempDir(), "test.db")
+	cfg.Name = "main"
+	cfg.Tracing = true
+	cfg.Metrics = reg
+	db, err := New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer Close(db)
+
+	if err := db.AutoMigrate(&txItem{}); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	if err := db.WithContext(ctx).Create(&txItem{ID: 1, Name: "a"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	var item txItem
+	if err := db.WithContext(ctx).First(&item, 2).Error; !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("First = %v", err)
+	}
+	if err := db.WithContext(ctx).Table("missing").First(&item).Error; err == nil {
+		t.Fatal("expected error querying a missing table")
+	}
+
+	mfs, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	values := map[string]float64{}
+	for _, mf := range mfs {
+		for _, m := range mf.GetMetric() {
+			labels := map[string]string{}
+			for _, lp := range m.GetLabel() {
+				labels[lp.GetName()] = lp.GetValue()
+			}
+			switch mf.GetName() {
+			case "db_query_duration_seconds":
+				values[mf.GetName()+"/"+labels["operation"]+"/"+labels["table"]] = float64(m.GetHistogram().GetSampleCount())
+			case "db_query_errors_total":
+				values[mf.GetName()+"/"+labels["operation"]+"/"+labels["table"]] = m.GetCounter().GetValue()
+			case "go_sql_open_connections":
+				values[mf.GetName()+"/"+labels["db_name"]] = m.GetGauge().GetValue()
+			}
+		}
+	}
+	for key, want := range map[string]float64{
+		"db_query_duration_seconds/create/tx_items": 1,
+		"db_query_duration_seconds/query/tx_items":  1,
+		"db_query_errors_total/query/missing":       1,
+	} {
+		if got := values[key]; got != want {
+			t.Errorf("%s = %v, want %v", key, got, want)
+		}
+	}
+	if _, ok := values["db_query_errors_total/query/tx_items"]; ok {
+		t.Error("ErrRecordNotFound counted as an error")
+	}
+	if _, ok := values["go_sql_open_connections/main"]; !ok {
+		t.Error("DBStats collector not registered")
+	}
+
+	spans := tp.Spans()
+	var failed int
+	for _, s := range spans {
+		if !s.Ended {
+			t.Errorf("span %s not ended", s.Name)
+		}
+		if s.Status == codes.Error {
+			failed++
+		}
+	}
+	if len(spans) < 3 || failed != 1 {
+		t.Fatalf("got %d spans with %d failures, want >= 3 with 1 failure", len(spans), failed)
+	}
+}
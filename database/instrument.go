@@ -0,0 +1,152 @@
+package database
+
+import (
+	"errors"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/shrimps80/go-service-utils/internal/prom"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+const (
+	instrumentStartKey = "database:instrument_start"
+	instrumentSpanKey  = "database:instrument_span"
+)
+
+// instrumentPlugin 为每条语句记录 OpenTelemetry span 与 Prometheus 查询耗时的 GORM 插件
+type instrumentPlugin struct {
+	name     string
+	dbSystem string
+	tracer   trace.Tracer
+	duration *prometheus.HistogramVec
+	errors   *prometheus.CounterVec
+}
+
+// newInstrumentPlugin 根据配置创建插件，未启用追踪与指标时返回 nil
+func newInstrumentPlugin(cfg *Config) *instrumentPlugin {
+	if !cfg.Tracing && cfg.Metrics == nil {
+		return nil
+	}
+
+	p := &instrumentPlugin{name: cfg.Name, dbSystem: cfg.Type}
+	if cfg.Tracing {
+		p.tracer = otel.GetTracerProvider().Tracer("github.com/shrimps80/go-service-utils/database")
+	}
+	if cfg.Metrics != nil {
+		p.duration = prom.Register(cfg.Metrics, prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "db_query_duration_seconds",
+				Help:    "Database query duration in seconds",
+				Buckets: []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5},
+			},
+			[]string{"db", "operation", "table"},
+		))
+		p.errors = prom.Register(cfg.Metrics, prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "db_query_errors_total",
+				Help: "Total number of failed database queries",
+			},
+			[]string{"db", "operation", "table"},
+		))
+	}
+	return p
+}
+
+// Name 实现 gorm.Plugin
+func (p *instrumentPlugin) Name() string {
+	return "database:instrument"
+}
+
+// Initialize 实现 gorm.Plugin，在各类语句执行前后注册回调
+func (p *instrumentPlugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	return errors.Join(
+		cb.Create().Before("gorm:create").Register("database:before_create", p.before("create")),
+		cb.Create().After("gorm:create").Register("database:after_create", p.after("create")),
+		cb.Query().Before("gorm:query").Register("database:before_query", p.before("query")),
+		cb.Query().After("gorm:query").Register("database:after_query", p.after("query")),
+		cb.Update().Before("gorm:update").Register("database:before_update", p.before("update")),
+		cb.Update().After("gorm:update").Register("database:after_update", p.after("update")),
+		cb.Delete().Before("gorm:delete").Register("database:before_delete", p.before("delete")),
+		cb.Delete().After("gorm:delete").Register("database:after_delete", p.after("delete")),
+		cb.Row().Before("gorm:row").Register("database:before_row", p.before("row")),
+		cb.Row().After("gorm:row").Register("database:after_row", p.after("row")),
+		cb.Raw().Before("gorm:raw").Register("database:before_raw", p.before("raw")),
+		cb.Raw().After("gorm:raw").Register("database:after_raw", p.after("raw")),
+	)
+}
+
+// before 记录开始时间并创建 span
+func (p *instrumentPlugin) before(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		db.InstanceSet(instrumentStartKey, time.Now())
+		if p.tracer == nil || db.Statement.Context == nil {
+			return
+		}
+		ctx, span := p.tracer.Start(db.Statement.Context, "gorm."+operation,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				attribute.String("db.system", p.dbSystem),
+				attribute.String("db.name", p.name),
+				attribute.String("db.operation", operation),
+			),
+		)
+		db.Statement.Context = ctx
+		db.InstanceSet(instrumentSpanKey, span)
+	}
+}
+
+// after 记录耗时、错误并结束 span；记录不存在（gorm.ErrRecordNotFound）不视为错误
+func (p *instrumentPlugin) after(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		err := db.Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			err = nil
+		}
+		table := db.Statement.Table
+
+		if v, ok := db.InstanceGet(instrumentStartKey); ok && p.duration != nil {
+			if start, ok := v.(time.Time); ok {
+				p.duration.WithLabelValues(p.name, operation, table).Observe(time.Since(start).Seconds())
+			}
+		}
+		if err != nil && p.errors != nil {
+			p.errors.WithLabelValues(p.name, operation, table).Inc()
+		}
+
+		v, ok := db.InstanceGet(instrumentSpanKey)
+		if !ok {
+			return
+		}
+		span, ok := v.(trace.Span)
+		if !ok {
+			return
+		}
+		span.SetAttributes(
+			attribute.String("db.sql.table", table),
+			attribute.String("db.statement", db.Statement.SQL.String()),
+			attribute.Int64("db.rows_affected", db.Statement.RowsAffected),
+		)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}
+}
+
+// registerDBStats 注册 sql.DBStats 连接池统计指标
+func registerDBStats(db *gorm.DB, cfg *Config) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	prom.Replace(cfg.Metrics, collectors.NewDBStatsCollector(sqlDB, cfg.Name))
+	return nil
+}
@@ -0,0 +1,121 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/shrimps80/go-service-utils/logger"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// gormLogger 将 GORM 日志输出到 logger.Logger，并附带上下文中的 trace_id
+type gormLogger struct {
+	log           *logger.Logger
+	level         gormlogger.LogLevel
+	slowThreshold time.Duration
+}
+
+// newGormLogger 创建基于 logger.Logger 的 GORM 日志
+func newGormLogger(log *logger.Logger, level gormlogger.LogLevel, slowThreshold time.Duration) gormlogger.Interface {
+	return &gormLogger{
+		log:           log.Named("gorm"),
+		level:         level,
+		slowThreshold: slowThreshold,
+	}
+}
+
+// LogMode 实现 gormlogger.Interface
+func (l *gormLogger) LogMode(level gormlogger.LogLevel) gormlogger.Interface {
+	newLogger := *l
+	newLogger.level = level
+	return &newLogger
+}
+
+// Info 实现 gormlogger.Interface
+func (l *gormLogger) Info(ctx context.Context, msg string, data ...interface{}) {
+	if l.level >= gormlogger.Info {
+		l.withTrace(ctx).Info(fmt.Sprintf(msg, data...))
+	}
+}
+
+// Warn 实现 gormlogger.Interface
+func (l *gormLogger) Warn(ctx context.Context, msg string, data ...interface{}) {
+	if l.level >= gormlogger.Warn {
+		l.withTrace(ctx).Warn(fmt.Sprintf(msg, data...))
+	}
+}
+
+// Error 实现 gormlogger.Interface
+func (l *gormLogger) Error(ctx context.Context, msg string, data ...interface{}) {
+	if l.level >= gormlogger.Error {
+		l.withTrace(ctx).Error(fmt.Sprintf(msg, data...))
+	}
+}
+
+// Trace 实现 gormlogger.Interface：记录失败语句、慢查询，Info 级别下记录所有语句
+func (l *gormLogger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	if l.level <= gormlogger.Silent {
+		return
+	}
+
+	elapsed := time.Since(begin)
+	switch {
+	case err != nil && l.level >= gormlogger.Error && !errors.Is(err, gorm.ErrRecordNotFound):
+		sql, rows := fc()
+		l.withTrace(ctx).Error("gorm query failed",
+			"error", err,
+			"sql", sql,
+			"rows", rows,
+			"elapsed_ms", elapsed.Milliseconds(),
+		)
+	case l.slowThreshold > 0 && elapsed > l.slowThreshold && l.level >= gormlogger.Warn:
+		sql, rows := fc()
+		l.withTrace(ctx).Warn("gorm slow query",
+			"sql", sql,
+			"rows", rows,
+			"elapsed_ms", elapsed.Milliseconds(),
+			"threshold_ms", l.slowThreshold.Milliseconds(),
+		)
+	case l.level >= gormlogger.Info:
+		sql, rows := fc()
+		l.withTrace(ctx).Info("gorm query",
+			"sql", sql,
+			"rows", rows,
+			"elapsed_ms", elapsed.Milliseconds(),
+		)
+	}
+}
+
+// withTrace 从上下文中提取 trace_id：优先取 OpenTelemetry span，其次取 middleware.Tracing 写入 gin.Context 的 trace_id
+func (l *gormLogger) withTrace(ctx context.Context) *logger.Logger {
+	if ctx == nil {
+		return l.log
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.HasTraceID() {
+		return l.log.With("trace_id", sc.TraceID().String())
+	}
+	if traceID, ok := ctx.Value("trace_id").(string); ok && traceID != "" {
+		return l.log.With("trace_id", traceID)
+	}
+	return l.log
+}
+
+// parseLogLevel 将字符串转换为 GORM 日志级别
+func parseLogLevel(level string) (gormlogger.LogLevel, error) {
+	switch level {
+	case "silent":
+		return gormlogger.Silent, nil
+	case "error":
+		return gormlogger.Error, nil
+	case "warn":
+		return gormlogger.Warn, nil
+	case "info":
+		return gormlogger.Info, nil
+	default:
+		return gormlogger.Silent, fmt.Errorf("unsupported log level: %s", level)
+	}
+}
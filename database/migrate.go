@@ -0,0 +1,465 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	mysqldriver "github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/mattn/go-sqlite3"
+	"github.com/shrimps80/go-service-utils/logger"
+	"gorm.io/gorm"
+)
+
+// 迁移错误定义
+var (
+	ErrNoDownMigration   = errors.New("migration has no down step")
+	ErrUnknownVersion    = errors.New("unknown migration version")
+	ErrDuplicateVersion  = errors.New("duplicate migration version")
+	ErrMigrationLockBusy = errors.New("migration lock is held by another process")
+)
+
+// MigrationFunc Go 迁移函数，tx 为该迁移所在的事务
+type MigrationFunc func(ctx context.Context, tx *gorm.DB) error
+
+// Migration 一个版本化的迁移
+type Migration struct {
+	Version int64
+	Name    string
+	Up      MigrationFunc
+	Down    MigrationFunc
+}
+
+// MigrationStatus 迁移状态
+type MigrationStatus struct {
+	Version   int64      `json:"version"`
+	Name      string     `json:"name"`
+	Applied   bool       `json:"applied"`
+	AppliedAt *time.Time `json:"applied_at,omitempty"`
+	Missing   bool       `json:"missing,omitempty"` // 已应用但在迁移源中找不到
+}
+
+// MigratorOptions 迁移器配置
+type MigratorOptions struct {
+	Table       string         // 记录已应用版本的表
+	LockName    string         // 跨进程迁移锁名称
+	LockTimeout time.Duration  // 获取迁移锁的超时时间
+	Logger      *logger.Logger // 不为空时记录每个迁移的执行
+}
+
+// DefaultMigratorOptions 返回默认的迁移器配置
+func DefaultMigratorOptions() *MigratorOptions {
+	return &MigratorOptions{
+		Table:       "schema_migrations",
+		LockName:    "schema_migrations",
+		LockTimeout: time.Minute,
+	}
+}
+
+// schemaMigration 版本记录
+type schemaMigration struct {
+	Version   int64  `gorm:"primaryKey;autoIncrement:false"`
+	Name      string `gorm:"size:255"`
+	AppliedAt time.Time
+}
+
+// Migrator 版本化迁移器：按版本顺序执行 SQL 文件或 Go 迁移函数，
+// 在表中记录已应用的版本，并通过数据库锁避免多个实例并发迁移
+type Migrator struct {
+	db         *gorm.DB
+	opts       *MigratorOptions
+	migrations map[int64]*Migration
+}
+
+// NewMigrator 创建迁移器
+func NewMigrator(db *gorm.DB, opts *MigratorOptions) *Migrator {
+	if opts == nil {
+		opts = DefaultMigratorOptions()
+	}
+	return &Migrator{
+		db:         db,
+		opts:       opts,
+		migrations: make(map[int64]*Migration),
+	}
+}
+
+var migrationFile = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+
+// LoadFS 从 fsys 的 dir 目录加载形如 0001_create_users.up.sql / 0001_create_users.down.sql 的迁移文件。
+// 每个文件作为一条语句执行，MySQL 中包含多条语句时需要在 DSN 中开启 multiStatements=true
+func (m *Migrator) LoadFS(fsys fs.FS, dir string) error {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		match := migrationFile.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return fmt.Errorf("migration %s: %w", entry.Name(), err)
+		}
+		content, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return err
+		}
+
+		mig, ok := m.migrations[version]
+		if !ok {
+			mig = &Migration{Version: version, Name: match[2]}
+			m.migrations[version] = mig
+		} else if mig.Name != match[2] {
+			return fmt.Errorf("%w: %d (%s, %s)", ErrDuplicateVersion, version, mig.Name, match[2])
+		}
+
+		fn := sqlMigration(string(content))
+		if match[3] == "up" {
+			if mig.Up != nil {
+				return fmt.Errorf("%w: %d", ErrDuplicateVersion, version)
+			}
+			mig.Up = fn
+		} else {
+			if mig.Down != nil {
+				return fmt.Errorf("%w: %d", ErrDuplicateVersion, version)
+			}
+			mig.Down = fn
+		}
+	}
+
+	return nil
+}
+
+// Register 注册一个 Go 迁移，down 可以为空
+func (m *Migrator) Register(version int64, name string, up, down MigrationFunc) error {
+	if _, ok := m.migrations[version]; ok {
+		return fmt.Errorf("%w: %d", ErrDuplicateVersion, version)
+	}
+	m.migrations[version] = &Migration{Version: version, Name: name, Up: up, Down: down}
+	return nil
+}
+
+// sqlMigration 将 SQL 文本包装为迁移函数
+func sqlMigration(query string) MigrationFunc {
+	return func(ctx context.Context, tx *gorm.DB) error {
+		return tx.Exec(query).Error
+	}
+}
+
+// Up 应用所有未执行的迁移
+func (m *Migrator) Up(ctx context.Context) error {
+	return m.run(ctx, func(conn *gorm.DB, applied map[int64]schemaMigration) error {
+		for _, mig := range m.sorted() {
+			if _, ok := applied[mig.Version]; ok {
+				continue
+			}
+			if err := m.apply(ctx, conn, mig, true); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Down 回滚最近应用的一个迁移
+func (m *Migrator) Down(ctx context.Context) error {
+	return m.run(ctx, func(conn *gorm.DB, applied map[int64]schemaMigration) error {
+		var latest int64 = -1
+		for version := range applied {
+			if version > latest {
+				latest = version
+			}
+		}
+		if latest < 0 {
+			return nil
+		}
+
+		mig, ok := m.migrations[latest]
+		if !ok {
+			return fmt.Errorf("%w: %d", ErrUnknownVersion, latest)
+		}
+		return m.apply(ctx, conn, mig, false)
+	})
+}
+
+// To 迁移到指定版本：应用不大于该版本的未执行迁移，并回滚大于该版本的已应用迁移。
+// version 为 0 时回滚全部迁移
+func (m *Migrator) To(ctx context.Context, version int64) error {
+	if _, ok := m.migrations[version]; !ok && version != 0 {
+		return fmt.Errorf("%w: %d", ErrUnknownVersion, version)
+	}
+
+	return m.run(ctx, func(conn *gorm.DB, applied map[int64]schemaMigration) error {
+		// 迁移源中缺失的已应用版本无法回滚
+		for v := range applied {
+			if _, ok := m.migrations[v]; !ok && v > version {
+				return fmt.Errorf("%w: %d", ErrUnknownVersion, v)
+			}
+		}
+
+		migs := m.sorted()
+
+		// 先按版本倒序回滚
+		for i := len(migs) - 1; i >= 0; i-- {
+			mig := migs[i]
+			if _, ok := applied[mig.Version]; ok && mig.Version > version {
+				if err := m.apply(ctx, conn, mig, false); err != nil {
+					return err
+				}
+			}
+		}
+
+		// 再按版本正序应用
+		for _, mig := range migs {
+			if _, ok := applied[mig.Version]; !ok && mig.Version <= version {
+				if err := m.apply(ctx, conn, mig, true); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// Status 返回所有迁移的状态，按版本升序排列
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	var statuses []MigrationStatus
+	err := m.db.WithContext(ctx).Connection(func(conn *gorm.DB) error {
+		applied, err := m.applied(conn)
+		if err != nil {
+			return err
+		}
+
+		for _, mig := range m.sorted() {
+			s := MigrationStatus{Version: mig.Version, Name: mig.Name}
+			if rec, ok := applied[mig.Version]; ok {
+				at := rec.AppliedAt
+				s.Applied = true
+				s.AppliedAt = &at
+			}
+			statuses = append(statuses, s)
+		}
+		for version, rec := range applied {
+			if _, ok := m.migrations[version]; !ok {
+				at := rec.AppliedAt
+				statuses = append(statuses, MigrationStatus{
+					Version: version, Name: rec.Name, Applied: true, AppliedAt: &at, Missing: true,
+				})
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Version < statuses[j].Version })
+	return statuses, nil
+}
+
+// run 在固定的连接上持有迁移锁并执行 fn
+func (m *Migrator) run(ctx context.Context, fn func(conn *gorm.DB, applied map[int64]schemaMigration) error) error {
+	return m.db.WithContext(ctx).Connection(func(conn *gorm.DB) error {
+		unlock, err := m.lock(ctx, conn)
+		if err != nil {
+			return err
+		}
+		defer unlock()
+
+		applied, err := m.applied(conn)
+		if err != nil {
+			return err
+		}
+		return fn(conn, applied)
+	})
+}
+
+// applied 创建版本表（如不存在）并返回已应用的版本
+func (m *Migrator) applied(conn *gorm.DB) (map[int64]schemaMigration, error) {
+	if err := conn.Table(m.opts.Table).AutoMigrate(&schemaMigration{}); err != nil {
+		return nil, fmt.Errorf("create migration table: %w", err)
+	}
+
+	var records []schemaMigration
+	if err := conn.Table(m.opts.Table).Order("version").Find(&records).Error; err != nil {
+		return nil, err
+	}
+
+	applied := make(map[int64]schemaMigration, len(records))
+	for _, rec := range records {
+		applied[rec.Version] = rec
+	}
+	return applied, nil
+}
+
+// apply 在事务中执行一个迁移并更新版本记录。
+// 注意 MySQL 的 DDL 会隐式提交，失败时可能需要手动修复
+func (m *Migrator) apply(ctx context.Context, conn *gorm.DB, mig *Migration, up bool) error {
+	fn, direction := mig.Up, "up"
+	if !up {
+		fn, direction = mig.Down, "down"
+	}
+	if fn == nil {
+		if up {
+			return fmt.Errorf("migration %d_%s has no up step", mig.Version, mig.Name)
+		}
+		return fmt.Errorf("%w: %d_%s", ErrNoDownMigration, mig.Version, mig.Name)
+	}
+
+	start := time.Now()
+	err := conn.Transaction(func(tx *gorm.DB) error {
+		if err := fn(ctx, tx); err != nil {
+			return err
+		}
+		if up {
+			return tx.Table(m.opts.Table).Create(&schemaMigration{
+				Version:   mig.Version,
+				Name:      mig.Name,
+				AppliedAt: time.Now(),
+			}).Error
+		}
+		return tx.Table(m.opts.Table).Where("version = ?", mig.Version).Delete(&schemaMigration{}).Error
+	})
+	if err != nil {
+		return fmt.Errorf("migration %d_%s %s: %w", mig.Version, mig.Name, direction, err)
+	}
+
+	if m.opts.Logger != nil {
+		m.opts.Logger.Info("migration applied",
+			"version", mig.Version,
+			"name", mig.Name,
+			"direction", direction,
+			"duration", time.Since(start).String(),
+		)
+	}
+	return nil
+}
+
+// lock 获取跨进程迁移锁，返回释放函数
+func (m *Migrator) lock(ctx context.Context, conn *gorm.DB) (func(), error) {
+	switch conn.Dialector.Name() {
+	case "mysql":
+		var ok int
+		timeout := int(m.opts.LockTimeout / time.Second)
+		if err := conn.Raw("SELECT GET_LOCK(?, ?)", m.opts.LockName, timeout).Scan(&ok).Error; err != nil {
+			return nil, err
+		}
+		if ok != 1 {
+			return nil, ErrMigrationLockBusy
+		}
+		return func() {
+			conn.Exec("SELECT RELEASE_LOCK(?)", m.opts.LockName)
+		}, nil
+
+	case "postgres":
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(m.opts.LockName))
+		key := int64(h.Sum64())
+
+		lockCtx, cancel := context.WithTimeout(ctx, m.opts.LockTimeout)
+		defer cancel()
+		if err := conn.WithContext(lockCtx).Exec("SELECT pg_advisory_lock(?)", key).Error; err != nil {
+			if lockCtx.Err() != nil {
+				return nil, ErrMigrationLockBusy
+			}
+			return nil, err
+		}
+		return func() {
+			conn.Exec("SELECT pg_advisory_unlock(?)", key)
+		}, nil
+
+	default:
+		return m.lockRow(ctx, conn)
+	}
+}
+
+// migrationLock 锁表中的一行，存在即表示有进程正在迁移
+type migrationLock struct {
+	Name     string `gorm:"primaryKey;size:255"`
+	LockedAt time.Time
+}
+
+// lockRow 通过向锁表插入以锁名为主键的行实现跨进程锁，用于没有咨询锁的数据库（如 SQLite）。
+// 行已存在（主键冲突）或数据库忙时每 100ms 重试一次，直到 LockTimeout，其他错误立即返回；
+// 进程在迁移中崩溃后需要手动删除 <Table>_lock 中的行
+func (m *Migrator) lockRow(ctx context.Context, conn *gorm.DB) (func(), error) {
+	table := m.opts.Table + "_lock"
+	conn = conn.Session(&gorm.Session{NewDB: true})
+	if err := conn.Table(table).AutoMigrate(&migrationLock{}); err != nil {
+		return nil, fmt.Errorf("create migration lock table: %w", err)
+	}
+
+	deadline := time.Now().Add(m.opts.LockTimeout)
+	for {
+		err := conn.Table(table).Create(&migrationLock{Name: m.opts.LockName, LockedAt: time.Now()}).Error
+		if err == nil {
+			return func() {
+				conn.Table(table).Where("name = ?", m.opts.LockName).Delete(&migrationLock{})
+			}, nil
+		}
+		if !isLockHeld(err) {
+			return nil, fmt.Errorf("acquire migration lock: %w", err)
+		}
+		if !time.Now().Before(deadline) {
+			return nil, fmt.Errorf("%w: %v", ErrMigrationLockBusy, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+}
+
+// isLockHeld 判断插入锁行的错误是否表示锁已被占用：主键或唯一键冲突，或 SQLite 数据库忙
+func isLockHeld(err error) bool {
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return true
+	}
+
+	var myErr *mysqldriver.MySQLError
+	if errors.As(err, &myErr) {
+		// 1062: 唯一键冲突
+		return myErr.Number == 1062
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		// 23505: 唯一键冲突
+		return pgErr.Code == "23505"
+	}
+
+	var liteErr sqlite3.Error
+	if errors.As(err, &liteErr) {
+		return liteErr.ExtendedCode == sqlite3.ErrConstraintPrimaryKey ||
+			liteErr.ExtendedCode == sqlite3.ErrConstraintUnique ||
+			liteErr.Code == sqlite3.ErrBusy || liteErr.Code == sqlite3.ErrLocked
+	}
+
+	return false
+}
+
+// sorted 返回按版本升序排列的迁移
+func (m *Migrator) sorted() []*Migration {
+	migs := make([]*Migration, 0, len(m.migrations))
+	for _, mig := range m.migrations {
+		migs = append(migs, mig)
+	}
+	sort.Slice(migs, func(i, j int) bool { return migs[i].Version < migs[j].Version })
+	return migs
+}
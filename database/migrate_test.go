@@ -0,0 +1,157 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func TestMigrator(t *testing.T) {
+	db := newTestDB(t).db
+	ctx := context.Background()
+
+	fsys := fstest.MapFS{
+		"migrations/0001_create_users.up.sql":   {Data: []byte("CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT)")},
+		"migrations/0001_create_users.down.sql": {Data: []byte("DROP TABLE users")},
+		"migrations/0002_add_email.up.sql":      {Data: []byte("ALTER TABLE users ADD COLUMN email TEXT")},
+		"migrations/0002_add_email.down.sql":    {Data: []byte("ALTER TABLE users DROP COLUMN email")},
+		"migrations/README.md":                  {Data: []byte("ignored")},
+	}
+
+	m := NewMigrator(db, nil)
+	if err := m.LoadFS(fsys, "migrations"); err != nil {
+		t.Fatal(err)
+	}
+	err := m.Register(3, "seed", func(ctx context.Context, tx *gorm.DB) error {
+		return tx.Exec("INSERT INTO users (name, email) VALUES ('admin', 'admin@example.com')").Error
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := m.Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+	assertApplied(t, m, 3)
+	if !db.Migrator().HasColumn("users", "email") {
+		t.Fatal("expected email column")
+	}
+
+	// 版本 3 没有 down，回滚失败且状态不变
+	if err := m.To(ctx, 1); err == nil {
+		t.Fatal("expected error for missing down step")
+	}
+	assertApplied(t, m, 3)
+
+	m.migrations[3].Down = func(ctx context.Context, tx *gorm.DB) error {
+		return tx.Exec("DELETE FROM users").Error
+	}
+	if err := m.To(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	assertApplied(t, m, 1)
+	if db.Migrator().HasColumn("users", "email") {
+		t.Fatal("expected email column to be dropped")
+	}
+
+	if err := m.Down(ctx); err != nil {
+		t.Fatal(err)
+	}
+	assertApplied(t, m, 0)
+	if db.Migrator().HasTable("users") {
+		t.Fatal("expected users table to be dropped")
+	}
+}
+
+// assertApplied 断言已应用的迁移恰好为 1..n
+func assertApplied(t *testing.T, m *Migrator, n int) {
+	t.Helper()
+
+	statuses, err := m.Status(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(statuses) != 3 {
+		t.Fatalf("expected 3 migrations, got %d", len(statuses))
+	}
+	for i, s := range statuses {
+		if want := i < n; s.Applied != want {
+			t.Errorf("migration %d: applied=%v, want %v", s.Version, s.Applied, want)
+		}
+	}
+}
+
+func TestMigratorLock(t *testing.T) {
+	db := newTestDB(t).db
+	ctx := context.Background()
+
+	var runs int
+	newMigrator := func() *Migrator {
+		opts := DefaultMigratorOptions()
+		opts.LockTimeout = 300 * time.Millisecond
+		m := NewMigrator(db, opts)
+		if err := m.Register(1, "count", func(ctx context.Context, tx *gorm.DB) error {
+			runs++
+			return nil
+		}, nil); err != nil {
+			t.Fatal(err)
+		}
+		return m
+	}
+	first, second := newMigrator(), newMigrator()
+
+	// 第一个迁移器持有锁期间，第二个迁移器超时返回 ErrMigrationLockBusy
+	err := first.db.Connection(func(conn *gorm.DB) error {
+		unlock, err := first.lock(ctx, conn)
+		if err != nil {
+			return err
+		}
+		defer unlock()
+
+		if err := second.Up(ctx); !errors.Is(err, ErrMigrationLockBusy) {
+			t.Errorf("expected ErrMigrationLockBusy, got %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 释放后可以获取锁，且已应用的版本不会重复执行
+	if err := second.Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := first.Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if runs != 1 {
+		t.Fatalf("migration ran %d times, want 1", runs)
+	}
+}
+
+func TestMigratorLockError(t *testing.T) {
+	db := newTestDB(t).db
+	ctx := context.Background()
+
+	// 锁表多出一个没有默认值的非空列，插入锁行失败但并非锁被占用
+	if err := db.Exec("CREATE TABLE schema_migrations_lock (name VARCHAR(255) PRIMARY KEY, locked_at DATETIME, owner TEXT NOT NULL)").Error; err != nil {
+		t.Fatal(err)
+	}
+	opts := DefaultMigratorOptions()
+	opts.LockTimeout = 5 * time.Second
+	m := NewMigrator(db, opts)
+
+	start := time.Now()
+	err := m.Up(ctx)
+	if err == nil || errors.Is(err, ErrMigrationLockBusy) || !strings.Contains(err.Error(), "acquire migration lock") {
+		t.Fatalf("got %v, want the insert error", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("non-conflict error retried for %v", elapsed)
+	}
+}
@@ -0,0 +1,442 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/shrimps80/go-service-utils/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 仓储错误定义
+var (
+	ErrInvalidSort   = errors.New("invalid sort field")
+	ErrInvalidFilter = errors.New("invalid filter")
+	ErrNoSoftDelete  = errors.New("model has no soft delete field")
+	ErrNoPrimaryKey  = errors.New("model has no primary key")
+)
+
+// PageQuery 分页与排序参数，可直接通过 ShouldBindQuery 绑定。
+// Sort 为逗号分隔的字段列表，前缀 - 表示降序，如 "-created_at,id"
+type PageQuery struct {
+	PageNum  int    `form:"pageNum" json:"pageNum"`
+	PageSize int    `form:"pageSize" json:"pageSize"`
+	Sort     string `form:"sort" json:"sort"`
+}
+
+// Page 分页结果，JSON 字段与 utils.PageData 一致
+type Page[T any] struct {
+	List     []T   `json:"list"`
+	PageNum  int   `json:"pageNum"`
+	PageSize int   `json:"pageSize"`
+	Total    int64 `json:"total"`
+}
+
+// ToPageData 转换为 utils.PageData，便于与 utils.Success 配合使用
+func (p *Page[T]) ToPageData() *utils.PageData {
+	return utils.NewPageData(p.List, p.PageNum, p.PageSize, p.Total)
+}
+
+// RepositoryOptions 仓储配置
+type RepositoryOptions struct {
+	SortFields      map[string]string // 允许排序的字段：请求中的字段名 -> 列名，不在其中的字段返回 ErrInvalidSort
+	DefaultSort     string            // 未指定排序时使用的排序，格式同 PageQuery.Sort
+	DefaultPageSize int               // 未指定每页条数时的默认值
+	MaxPageSize     int               // 每页条数上限
+	BatchSize       int               // 批量写入时每批的条数
+}
+
+// DefaultRepositoryOptions 返回默认的仓储配置
+func DefaultRepositoryOptions() *RepositoryOptions {
+	return &RepositoryOptions{
+		DefaultPageSize: 20,
+		MaxPageSize:     100,
+		BatchSize:       100,
+	}
+}
+
+// trashedMode 软删除记录的查询方式
+type trashedMode int
+
+const (
+	withoutTrashed trashedMode = iota
+	withTrashed
+	onlyTrashed
+)
+
+// Repository 泛型仓储：封装按主键查询、分页查询、批量写入等常用操作。
+// 所有方法优先使用 ctx 中由 TxManager 开启的事务
+type Repository[T any] struct {
+	db      *gorm.DB
+	opts    *RepositoryOptions
+	trashed trashedMode
+}
+
+// NewRepository 创建泛型仓储
+func NewRepository[T any](db *gorm.DB, opts *RepositoryOptions) *Repository[T] {
+	if opts == nil {
+		opts = DefaultRepositoryOptions()
+	}
+	return &Repository[T]{db: db, opts: opts}
+}
+
+// WithTrashed 返回包含软删除记录的仓储视图，视图上的 Delete 为物理删除
+func (r *Repository[T]) WithTrashed() *Repository[T] {
+	clone := *r
+	clone.trashed = withTrashed
+	return &clone
+}
+
+// OnlyTrashed 返回仅查询软删除记录的仓储视图，模型没有软删除字段时查询返回 ErrNoSoftDelete
+func (r *Repository[T]) OnlyTrashed() *Repository[T] {
+	clone := *r
+	clone.trashed = onlyTrashed
+	return &clone
+}
+
+// DB 返回当前应使用的数据库会话：ctx 中存在事务时返回该事务
+func (r *Repository[T]) DB(ctx context.Context) *gorm.DB {
+	if tx, ok := FromContext(ctx); ok {
+		return tx
+	}
+	return r.db.WithContext(ctx)
+}
+
+// query 返回应用了软删除方式的模型查询
+func (r *Repository[T]) query(ctx context.Context) (*gorm.DB, error) {
+	db := r.DB(ctx).Model(new(T))
+
+	switch r.trashed {
+	case withTrashed:
+		db = db.Unscoped()
+	case onlyTrashed:
+		column, err := r.deletedColumn()
+		if err != nil {
+			return nil, err
+		}
+		db = db.Unscoped().Where(clause.Neq{
+			Column: clause.Column{Table: clause.CurrentTable, Name: column},
+			Value:  nil,
+		})
+	}
+	return db, nil
+}
+
+// deletedColumn 返回模型的软删除列
+func (r *Repository[T]) deletedColumn() (string, error) {
+	stmt := &gorm.Statement{DB: r.db}
+	if err := stmt.Parse(new(T)); err != nil {
+		return "", err
+	}
+
+	deletedAt := reflect.TypeOf(gorm.DeletedAt{})
+	for _, field := range stmt.Schema.Fields {
+		if field.FieldType == deletedAt {
+			return field.DBName, nil
+		}
+	}
+	return "", ErrNoSoftDelete
+}
+
+// primaryKeyCond 构造按主键相等的条件；id 始终作为绑定参数，字符串主键不会被当作 SQL 片段
+func (r *Repository[T]) primaryKeyCond(id any) (clause.Expression, error) {
+	stmt := &gorm.Statement{DB: r.db}
+	if err := stmt.Parse(new(T)); err != nil {
+		return nil, err
+	}
+	if stmt.Schema.PrioritizedPrimaryField == nil {
+		return nil, ErrNoPrimaryKey
+	}
+	return clause.Eq{
+		Column: clause.Column{Table: clause.CurrentTable, Name: stmt.Schema.PrioritizedPrimaryField.DBName},
+		Value:  id,
+	}, nil
+}
+
+// FindByID 按主键查询，记录不存在时返回 gorm.ErrRecordNotFound
+func (r *Repository[T]) FindByID(ctx context.Context, id any) (*T, error) {
+	cond, err := r.primaryKeyCond(id)
+	if err != nil {
+		return nil, err
+	}
+	db, err := r.query(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var entity T
+	if err := db.Clauses(clause.Where{Exprs: []clause.Expression{cond}}).First(&entity).Error; err != nil {
+		return nil, err
+	}
+	return &entity, nil
+}
+
+// FindPage 分页查询。filter 为带 filter 标签的结构体（或其指针），只有声明了标签且非零值的字段参与过滤，
+// 标签格式为 `filter:"column,op"`，op 支持 eq（默认）、ne、gt、gte、lt、lte、like、prefix、in；
+// scopes 用于追加预加载、关联条件等
+func (r *Repository[T]) FindPage(ctx context.Context, q PageQuery, filter any, scopes ...func(*gorm.DB) *gorm.DB) (*Page[T], error) {
+	pageNum, pageSize := r.normalizePage(q)
+
+	orders, err := r.parseSort(q.Sort)
+	if err != nil {
+		return nil, err
+	}
+	conds, err := buildFilters(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := r.query(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(conds) > 0 {
+		db = db.Clauses(clause.Where{Exprs: conds})
+	}
+	db = db.Scopes(scopes...).Session(&gorm.Session{})
+
+	page := &Page[T]{List: []T{}, PageNum: pageNum, PageSize: pageSize}
+	if err := db.Count(&page.Total).Error; err != nil {
+		return nil, err
+	}
+	if page.Total == 0 || int64((pageNum-1)*pageSize) >= page.Total {
+		return page, nil
+	}
+
+	find := db.Offset((pageNum - 1) * pageSize).Limit(pageSize)
+	if len(orders) > 0 {
+		find = find.Order(clause.OrderBy{Columns: orders})
+	}
+	if err := find.Find(&page.List).Error; err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+// Create 插入一条记录
+func (r *Repository[T]) Create(ctx context.Context, entity *T) error {
+	return r.DB(ctx).Create(entity).Error
+}
+
+// CreateBatch 按 BatchSize 分批插入
+func (r *Repository[T]) CreateBatch(ctx context.Context, entities []T) error {
+	if len(entities) == 0 {
+		return nil
+	}
+	return r.DB(ctx).CreateInBatches(entities, r.batchSize()).Error
+}
+
+// Upsert 分批插入，conflictColumns 冲突时更新 updateColumns；updateColumns 为空时更新全部字段
+func (r *Repository[T]) Upsert(ctx context.Context, entities []T, conflictColumns []string, updateColumns ...string) error {
+	if len(entities) == 0 {
+		return nil
+	}
+
+	onConflict := clause.OnConflict{}
+	for _, column := range conflictColumns {
+		onConflict.Columns = append(onConflict.Columns, clause.Column{Name: column})
+	}
+	if len(updateColumns) > 0 {
+		onConflict.DoUpdates = clause.AssignmentColumns(updateColumns)
+	} else {
+		onConflict.UpdateAll = true
+	}
+
+	return r.DB(ctx).Clauses(onConflict).CreateInBatches(entities, r.batchSize()).Error
+}
+
+// Delete 按主键删除，模型有软删除字段时为软删除，WithTrashed 视图上为物理删除
+func (r *Repository[T]) Delete(ctx context.Context, id any) error {
+	cond, err := r.primaryKeyCond(id)
+	if err != nil {
+		return err
+	}
+	db := r.DB(ctx)
+	if r.trashed != withoutTrashed {
+		db = db.Unscoped()
+	}
+	return db.Clauses(clause.Where{Exprs: []clause.Expression{cond}}).Delete(new(T)).Error
+}
+
+// normalizePage 规范化页码与每页条数
+func (r *Repository[T]) normalizePage(q PageQuery) (int, int) {
+	pageNum, pageSize := q.PageNum, q.PageSize
+	if pageNum < 1 {
+		pageNum = 1
+	}
+	if pageSize < 1 {
+		pageSize = r.opts.DefaultPageSize
+	}
+	if r.opts.MaxPageSize > 0 && pageSize > r.opts.MaxPageSize {
+		pageSize = r.opts.MaxPageSize
+	}
+	if pageSize < 1 {
+		pageSize = 20
+	}
+	return pageNum, pageSize
+}
+
+// batchSize 返回批量写入的每批条数
+func (r *Repository[T]) batchSize() int {
+	if r.opts.BatchSize > 0 {
+		return r.opts.BatchSize
+	}
+	return 100
+}
+
+// parseSort 按白名单解析排序参数
+func (r *Repository[T]) parseSort(sort string) ([]clause.OrderByColumn, error) {
+	if sort == "" {
+		sort = r.opts.DefaultSort
+	}
+
+	var orders []clause.OrderByColumn
+	for _, field := range strings.Split(sort, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+
+		desc := strings.HasPrefix(field, "-")
+		field = strings.TrimLeft(field, "+-")
+
+		column, ok := r.opts.SortFields[field]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidSort, field)
+		}
+		orders = append(orders, clause.OrderByColumn{
+			Column: clause.Column{Table: clause.CurrentTable, Name: column},
+			Desc:   desc,
+		})
+	}
+	return orders, nil
+}
+
+// buildFilters 将带 filter 标签的结构体转换为查询条件
+func buildFilters(filter any) ([]clause.Expression, error) {
+	if filter == nil {
+		return nil, nil
+	}
+
+	v := reflect.ValueOf(filter)
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil, nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%w: expected struct, got %s", ErrInvalidFilter, v.Kind())
+	}
+	return structFilters(v)
+}
+
+// structFilters 遍历结构体字段生成条件；匿名嵌入的结构体（包括未导出类型）直接按 reflect.Value 展开
+func structFilters(v reflect.Value) ([]clause.Expression, error) {
+	var conds []clause.Expression
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		value := v.Field(i)
+
+		tag, ok := field.Tag.Lookup("filter")
+		if !ok {
+			// 展开匿名嵌入的过滤结构体
+			if field.Anonymous {
+				for value.Kind() == reflect.Ptr && !value.IsNil() {
+					value = value.Elem()
+				}
+				if value.Kind() != reflect.Struct {
+					continue
+				}
+				nested, err := structFilters(value)
+				if err != nil {
+					return nil, err
+				}
+				conds = append(conds, nested...)
+			}
+			continue
+		}
+		if tag == "-" || !field.IsExported() {
+			continue
+		}
+
+		// nil 指针与零值不参与过滤，需要按零值过滤时使用指针字段
+		if value.Kind() == reflect.Ptr {
+			if value.IsNil() {
+				continue
+			}
+			value = value.Elem()
+		} else if value.IsZero() {
+			continue
+		}
+
+		column, op, _ := strings.Cut(tag, ",")
+		if column == "" {
+			return nil, fmt.Errorf("%w: field %s has no column", ErrInvalidFilter, field.Name)
+		}
+		cond, err := filterExpr(clause.Column{Table: clause.CurrentTable, Name: column}, op, value)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", field.Name, err)
+		}
+		if cond != nil {
+			conds = append(conds, cond)
+		}
+	}
+	return conds, nil
+}
+
+// filterExpr 根据操作符构造单个条件
+func filterExpr(column clause.Column, op string, value reflect.Value) (clause.Expression, error) {
+	v := value.Interface()
+
+	switch op {
+	case "", "eq":
+		return clause.Eq{Column: column, Value: v}, nil
+	case "ne":
+		return clause.Neq{Column: column, Value: v}, nil
+	case "gt":
+		return clause.Gt{Column: column, Value: v}, nil
+	case "gte":
+		return clause.Gte{Column: column, Value: v}, nil
+	case "lt":
+		return clause.Lt{Column: column, Value: v}, nil
+	case "lte":
+		return clause.Lte{Column: column, Value: v}, nil
+	case "like":
+		return likeExpr(column, "%"+escapeLike(fmt.Sprint(v))+"%"), nil
+	case "prefix":
+		return likeExpr(column, escapeLike(fmt.Sprint(v))+"%"), nil
+	case "in":
+		if value.Kind() != reflect.Slice && value.Kind() != reflect.Array {
+			return nil, fmt.Errorf("%w: in requires a slice", ErrInvalidFilter)
+		}
+		if value.Len() == 0 {
+			return nil, nil
+		}
+		values := make([]any, value.Len())
+		for i := range values {
+			values[i] = value.Index(i).Interface()
+		}
+		return clause.IN{Column: column, Values: values}, nil
+	default:
+		return nil, fmt.Errorf("%w: unknown operator %q", ErrInvalidFilter, op)
+	}
+}
+
+// likeEscaper 转义 LIKE 模式中的通配符，用户输入中的 % 与 _ 按字面匹配
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// escapeLike 转义 LIKE 模式中的特殊字符
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
+
+// likeExpr 构造以反斜杠为转义字符的 LIKE 条件
+func likeExpr(column clause.Column, pattern string) clause.Expression {
+	return clause.Expr{SQL: "? LIKE ? ESCAPE ?", Vars: []any{column, pattern, `\`}}
+}
@@ -0,0 +1,259 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/shrimps80/go-service-utils/utils"
+	"gorm.io/gorm"
+)
+
+type repoUser struct {
+	ID        int
+	Name      string `gorm:"uniqueIndex"`
+	Age       int
+	DeletedAt gorm.DeletedAt
+}
+
+type repoUserFilter struct {
+	Name   string `filter:"name,prefix"`
+	MinAge *int   `filter:"age,gte"`
+	IDs    []int  `filter:"id,in"`
+	Ignore string
+}
+
+func newTestRepo(t *testing.T) *Repository[repoUser] {
+	t.Helper()
+
+	db := newTestDB(t).db
+	if err := db.AutoMigrate(&repoUser{}); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := DefaultRepositoryOptions()
+	opts.SortFields = map[string]string{"id": "id", "age": "age"}
+	opts.DefaultSort = "id"
+	opts.BatchSize = 2
+	return NewRepository[repoUser](db, opts)
+}
+
+func TestRepositoryFindPage(t *testing.T) {
+	repo := newTestRepo(t)
+	ctx := context.Background()
+
+	users := []repoUser{
+		{ID: 1, Name: "alice", Age: 30},
+		{ID: 2, Name: "bob", Age: 0},
+		{ID: 3, Name: "alan", Age: 20},
+		{ID: 4, Name: "amy", Age: 40},
+		{ID: 5, Name: "al", Age: 50},
+	}
+	if err := repo.CreateBatch(ctx, users); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Delete(ctx, 5); err != nil {
+		t.Fatal(err)
+	}
+
+	minAge := 0
+	page, err := repo.FindPage(ctx, PageQuery{PageNum: 1, PageSize: 2, Sort: "-age"}, &repoUserFilter{Name: "a", MinAge: &minAge, Ignore: "x"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.Total != 3 || len(page.List) != 2 || page.List[0].ID != 4 || page.List[1].ID != 1 {
+		t.Fatalf("unexpected page: %+v", page)
+	}
+
+	page, err = repo.FindPage(ctx, PageQuery{}, repoUserFilter{IDs: []int{2, 5}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.Total != 1 || page.PageSize != 20 || page.List[0].ID != 2 {
+		t.Fatalf("unexpected page: %+v", page)
+	}
+
+	if _, err := repo.FindPage(ctx, PageQuery{Sort: "name"}, nil); !errors.Is(err, ErrInvalidSort) {
+		t.Fatalf("expected ErrInvalidSort, got %v", err)
+	}
+
+	page, err = repo.OnlyTrashed().FindPage(ctx, PageQuery{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.Total != 1 || page.List[0].ID != 5 {
+		t.Fatalf("unexpected trashed page: %+v", page)
+	}
+	if data := page.ToPageData(); data.Total != 1 || data.PageNum != 1 {
+		t.Fatalf("unexpected page data: %+v", data)
+	}
+}
+
+func TestRepositoryUpsertAndFind(t *testing.T) {
+	repo := newTestRepo(t)
+	ctx := context.Background()
+
+	if err := repo.Create(ctx, &repoUser{ID: 1, Name: "alice", Age: 30}); err != nil {
+		t.Fatal(err)
+	}
+	err := repo.Upsert(ctx, []repoUser{{ID: 1, Name: "alice", Age: 31}, {ID: 2, Name: "bob", Age: 20}}, []string{"id"}, "age")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user, err := repo.FindByID(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.Age != 31 {
+		t.Errorf("expected age 31, got %d", user.Age)
+	}
+
+	if _, err := repo.FindByID(ctx, 3); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("expected ErrRecordNotFound, got %v", err)
+	}
+}
+
+func TestRepositoryFindCursorPage(t *testing.T) {
+	repo := newTestRepo(t)
+	ctx := context.Background()
+	codec := utils.NewCursorCodec([]byte("secret"))
+
+	// 年龄有重复，以 id 作为唯一的次级排序键
+	ages := []int{30, 20, 30, 10, 20, 30, 40}
+	for i, age := range ages {
+		if err := repo.Create(ctx, &repoUser{ID: i + 1, Name: fmt.Sprint("u", i+1), Age: age}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	keys := []SortKey{{Column: "age", Desc: true}, {Column: "id"}}
+
+	var ids []int
+	var pages []*CursorPage[repoUser]
+	q := CursorQuery{PageSize: 3}
+	for {
+		page, err := repo.FindCursorPage(ctx, codec, q, keys, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pages = append(pages, page)
+		for _, u := range page.List {
+			ids = append(ids, u.ID)
+		}
+		if !page.HasMore {
+			break
+		}
+		q.Cursor = page.NextCursor
+	}
+
+	if want := "[7 1 3 6 2 5 4]"; fmt.Sprint(ids) != want {
+		t.Fatalf("ids = %v, want %s", ids, want)
+	}
+	if len(pages) != 3 || pages[0].PrevCursor != "" || pages[2].NextCursor != "" {
+		t.Fatalf("unexpected cursors: %+v", pages)
+	}
+
+	// 从最后一页向前翻页
+	prev, err := repo.FindCursorPage(ctx, codec, CursorQuery{Cursor: pages[2].PrevCursor, PageSize: 3}, keys, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := fmt.Sprint(prev.List[0].ID, prev.List[1].ID, prev.List[2].ID); got != "6 2 5" {
+		t.Fatalf("prev page = %s, want 6 2 5", got)
+	}
+	if prev.PrevCursor == "" || prev.NextCursor == "" {
+		t.Fatalf("expected both cursors: %+v", prev)
+	}
+
+	if _, err := repo.FindCursorPage(ctx, codec, CursorQuery{Cursor: "bogus"}, keys, nil); !errors.Is(err, utils.ErrInvalidCursor) {
+		t.Fatalf("expected ErrInvalidCursor, got %v", err)
+	}
+}
+
+type repoToken struct {
+	Code string `gorm:"primaryKey"`
+	Note string
+}
+
+type pageFilter struct {
+	Name string `filter:"name,like"`
+}
+
+type embeddedUserFilter struct {
+	pageFilter
+	MaxAge int `filter:"age,lte"`
+}
+
+func TestRepositoryPrimaryKeyIsBound(t *testing.T) {
+	repo := newTestRepo(t)
+	ctx := context.Background()
+	for i := 1; i <= 3; i++ {
+		if err := repo.Create(ctx, &repoUser{ID: i, Name: fmt.Sprint("u", i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := repo.FindByID(ctx, "0 OR 1=1"); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("FindByID with injected id: expected ErrRecordNotFound, got %v", err)
+	}
+	if err := repo.WithTrashed().Delete(ctx, "1=1"); err != nil {
+		t.Fatal(err)
+	}
+	page, err := repo.FindPage(ctx, PageQuery{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.Total != 3 {
+		t.Fatalf("injected delete removed rows: %d left", page.Total)
+	}
+
+	tokens := NewRepository[repoToken](repo.db, nil)
+	if err := repo.db.AutoMigrate(&repoToken{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := tokens.Create(ctx, &repoToken{Code: "a-b-c", Note: "x"}); err != nil {
+		t.Fatal(err)
+	}
+	token, err := tokens.FindByID(ctx, "a-b-c")
+	if err != nil || token.Note != "x" {
+		t.Fatalf("FindByID string key = %+v, %v", token, err)
+	}
+	if err := tokens.Delete(ctx, "a-b-c"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tokens.FindByID(ctx, "a-b-c"); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("expected ErrRecordNotFound after delete, got %v", err)
+	}
+}
+
+func TestRepositoryFilterEscapingAndEmbedding(t *testing.T) {
+	repo := newTestRepo(t)
+	ctx := context.Background()
+	users := []repoUser{
+		{ID: 1, Name: "50%_off", Age: 10},
+		{ID: 2, Name: "50 dollars", Age: 20},
+		{ID: 3, Name: `back\slash`, Age: 30},
+	}
+	if err := repo.CreateBatch(ctx, users); err != nil {
+		t.Fatal(err)
+	}
+
+	for filter, want := range map[string]int64{"%": 1, "_": 1, "50%": 1, `\`: 1, "50": 2} {
+		page, err := repo.FindPage(ctx, PageQuery{}, embeddedUserFilter{pageFilter: pageFilter{Name: filter}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if page.Total != want {
+			t.Errorf("like %q matched %d rows, want %d", filter, page.Total, want)
+		}
+	}
+
+	page, err := repo.FindPage(ctx, PageQuery{}, &embeddedUserFilter{pageFilter: pageFilter{Name: "50"}, MaxAge: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.Total != 1 || page.List[0].ID != 1 {
+		t.Fatalf("unexpected page: %+v", page)
+	}
+}
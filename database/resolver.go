@@ -0,0 +1,246 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math/rand"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/shrimps80/go-service-utils/logger"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// 副本负载均衡策略
+const (
+	ReplicaPolicyRandom     = "random"      // 随机选择
+	ReplicaPolicyRoundRobin = "round_robin" // 轮询
+)
+
+const resolverPluginName = "database:resolver"
+
+type usePrimaryKey struct{}
+
+// UsePrimary 返回强制走主库的上下文，用于写后立即读（read-after-write）等不能容忍副本延迟的场景
+func UsePrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, usePrimaryKey{}, true)
+}
+
+// Primary 返回强制走主库的会话
+func Primary(db *gorm.DB) *gorm.DB {
+	ctx := db.Statement.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return db.WithContext(UsePrimary(ctx))
+}
+
+// replica 只读副本
+type replica struct {
+	db      *sql.DB
+	healthy atomic.Bool
+}
+
+// resolver 读写分离插件：事务外的读请求路由到健康的副本，写请求与事务始终走主库
+type resolver struct {
+	primary  gorm.ConnPool
+	replicas []*replica
+	policy   string
+	next     atomic.Uint64
+	interval time.Duration
+	log      *logger.Logger
+
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// newResolver 打开所有副本连接，任一副本连接失败时关闭已打开的副本并返回错误
+func newResolver(ctx context.Context, cfg *Config) (*resolver, error) {
+	r := &resolver{
+		policy:   cfg.ReplicaPolicy,
+		interval: cfg.ReplicaHealthInterval,
+		log:      cfg.Logger,
+		stopCh:   make(chan struct{}),
+	}
+
+	for _, dsn := range cfg.Replicas {
+		db, err := openWithRetry(ctx, cfg, dsn, &gorm.Config{Logger: gormlogger.Discard})
+		if err != nil {
+			r.closeReplicas()
+			return nil, err
+		}
+		sqlDB, err := db.DB()
+		if err != nil {
+			r.closeReplicas()
+			return nil, err
+		}
+		configurePool(sqlDB, cfg)
+
+		rep := &replica{db: sqlDB}
+		rep.healthy.Store(true)
+		r.replicas = append(r.replicas, rep)
+	}
+
+	return r, nil
+}
+
+// Name 实现 gorm.Plugin
+func (r *resolver) Name() string {
+	return resolverPluginName
+}
+
+// Initialize 实现 gorm.Plugin：在查询执行前选择连接，并启动副本健康检查
+func (r *resolver) Initialize(db *gorm.DB) error {
+	r.primary = db.ConnPool
+
+	cb := db.Callback()
+	if err := errors.Join(
+		cb.Query().Before("gorm:query").Register("database:resolver_query", r.route),
+		cb.Row().Before("gorm:row").Register("database:resolver_row", r.route),
+	); err != nil {
+		return err
+	}
+
+	if r.interval > 0 {
+		r.wg.Add(1)
+		go r.healthLoop()
+	}
+	return nil
+}
+
+// route 将事务外的读请求切换到副本连接
+func (r *resolver) route(db *gorm.DB) {
+	stmt := db.Statement
+
+	// 事务中（ConnPool 为 *sql.Tx）或已显式指定连接时不切换
+	if stmt.ConnPool != r.primary {
+		return
+	}
+	if stmt.Context != nil {
+		if force, _ := stmt.Context.Value(usePrimaryKey{}).(bool); force {
+			return
+		}
+	}
+	// SELECT ... FOR UPDATE 等加锁读必须走主库
+	if _, locking := stmt.Clauses["FOR"]; locking {
+		return
+	}
+	// 原生 SQL 只切换只读语句
+	if sql := stmt.SQL.String(); sql != "" && !isReadQuery(sql) {
+		return
+	}
+
+	if rep := r.pick(); rep != nil {
+		stmt.ConnPool = rep.db
+	}
+}
+
+// pick 按策略选择一个健康的副本，没有健康副本时返回 nil（回退到主库）
+func (r *resolver) pick() *replica {
+	healthy := make([]*replica, 0, len(r.replicas))
+	for _, rep := range r.replicas {
+		if rep.healthy.Load() {
+			healthy = append(healthy, rep)
+		}
+	}
+	if len(healthy) == 0 {
+		return nil
+	}
+
+	if r.policy == ReplicaPolicyRoundRobin {
+		return healthy[(r.next.Add(1)-1)%uint64(len(healthy))]
+	}
+	return healthy[rand.Intn(len(healthy))]
+}
+
+// healthLoop 定期探测副本，探测失败的副本被摘除，恢复后重新加入
+func (r *resolver) healthLoop() {
+	defer r.wg.Done()
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.stopCh:
+			return
+		case <-ticker.C:
+			r.checkReplicas()
+		}
+	}
+}
+
+// checkReplicas 探测所有副本的可用性
+func (r *resolver) checkReplicas() {
+	timeout := r.interval / 2
+	if timeout > 5*time.Second {
+		timeout = 5 * time.Second
+	}
+
+	for i, rep := range r.replicas {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		err := rep.db.PingContext(ctx)
+		cancel()
+
+		healthy := err == nil
+		if rep.healthy.Swap(healthy) != healthy && r.log != nil {
+			if healthy {
+				r.log.Info("database replica recovered", "replica", i)
+			} else {
+				r.log.Warn("database replica evicted", "replica", i, "error", err)
+			}
+		}
+	}
+}
+
+// close 停止健康检查并关闭所有副本连接
+func (r *resolver) close() error {
+	r.stopOnce.Do(func() {
+		close(r.stopCh)
+	})
+	r.wg.Wait()
+	return r.closeReplicas()
+}
+
+// closeReplicas 关闭所有副本连接
+func (r *resolver) closeReplicas() error {
+	var errs []error
+	for _, rep := range r.replicas {
+		if err := rep.db.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// isReadQuery 判断原生 SQL 是否为只读查询
+func isReadQuery(sql string) bool {
+	sql = strings.ToUpper(strings.TrimSpace(sql))
+	if !strings.HasPrefix(sql, "SELECT") && !strings.HasPrefix(sql, "WITH") && !strings.HasPrefix(sql, "SHOW") {
+		return false
+	}
+	return !strings.Contains(sql, "FOR UPDATE") && !strings.Contains(sql, "FOR SHARE") && !strings.Contains(sql, "LOCK IN SHARE MODE")
+}
+
+// Close 关闭数据库连接，包括读写分离的副本连接与后台健康检查
+func Close(db *gorm.DB) error {
+	var errs []error
+	if p, ok := db.Config.Plugins[resolverPluginName]; ok {
+		if r, ok := p.(*resolver); ok {
+			errs = append(errs, r.close())
+		}
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		errs = append(errs, err)
+	} else {
+		errs = append(errs, sqlDB.Close())
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,156 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// newResolverDB 创建主库与 n 个副本（各自为独立的 SQLite 文件），每个库中 id=1 的记录名称标明其来源
+func newResolverDB(t *testing.T, n int, policy string, interval time.Duration) *gorm.DB {
+	t.Helper()
+	dir := t.TempDir()
+
+	cfg := DefaultConfig()
+	cfg.Type = "sqlite"
+	cfg.DSN = filepath.Join(dir, "primary.db")
+	cfg.ReplicaPolicy = policy
+	cfg.ReplicaHealthInterval = interval
+	for i := 0; i < n; i++ {
+		dsn := filepath.Join(dir, fmt.Sprintf("replica%d.db", i))
+		seedResolverDB(t, dsn, fmt.Sprintf("replica%d", i))
+		cfg.Replicas = append(cfg.Replicas, dsn)
+	}
+	seedResolverDB(t, cfg.DSN, "primary")
+
+	db, err := New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = Close(db) })
+	return db
+}
+
+func seedResolverDB(t *testing.T, dsn, name string) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{Logger: gormlogger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&txItem{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Create(&txItem{ID: 1, Name: name}).Error; err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, _ := db.DB()
+	_ = sqlDB.Close()
+}
+
+// readName 读取 id=1 的记录名称，即本次查询实际使用的库
+func readName(t *testing.T, db *gorm.DB) string {
+	t.Helper()
+	var item txItem
+	if err := db.First(&item, 1).Error; err != nil {
+		t.Fatal(err)
+	}
+	return item.Name
+}
+
+func TestResolverRouting(t *testing.T) {
+	db := newResolverDB(t, 2, ReplicaPolicyRoundRobin, 0)
+	ctx := context.Background()
+
+	// 读请求在副本之间轮询
+	got := []string{readName(t, db), readName(t, db), readName(t, db)}
+	if fmt.Sprint(got) != "[replica0 replica1 replica0]" {
+		t.Fatalf("round robin reads = %v", got)
+	}
+
+	var name string
+	if err := db.Raw("SELECT name FROM tx_items WHERE id = 1").Scan(&name).Error; err != nil {
+		t.Fatal(err)
+	}
+	if name != "replica1" {
+		t.Fatalf("raw read went to %s, want replica1", name)
+	}
+
+	// 强制主库、加锁读与事务内的读都走主库
+	if got := readName(t, db.WithContext(UsePrimary(ctx))); got != "primary" {
+		t.Errorf("UsePrimary read went to %s", got)
+	}
+	if got := readName(t, Primary(db)); got != "primary" {
+		t.Errorf("Primary read went to %s", got)
+	}
+	if got := readName(t, db.Clauses(clause.Locking{Strength: "UPDATE"})); got != "primary" {
+		t.Errorf("FOR UPDATE read went to %s", got)
+	}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if got := readName(t, tx); got != "primary" {
+			t.Errorf("read in transaction went to %s", got)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 写请求走主库
+	if err := db.Create(&txItem{ID: 2, Name: "written"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	var n int64
+	if err := Primary(db).Model(&txItem{}).Count(&n).Error; err != nil || n != 2 {
+		t.Fatalf("primary count = %d, %v; want 2", n, err)
+	}
+	if err := db.Model(&txItem{}).Count(&n).Error; err != nil || n != 1 {
+		t.Fatalf("replica count = %d, %v; want 1", n, err)
+	}
+}
+
+func TestResolverHealthEviction(t *testing.T) {
+	db := newResolverDB(t, 1, ReplicaPolicyRandom, 10*time.Millisecond)
+	if got := readName(t, db); got != "replica0" {
+		t.Fatalf("read went to %s, want replica0", got)
+	}
+
+	// 副本不可用后被健康检查摘除，读请求回退到主库
+	r := db.Config.Plugins[resolverPluginName].(*resolver)
+	_ = r.replicas[0].db.Close()
+	deadline := time.Now().Add(2 * time.Second)
+	for r.replicas[0].healthy.Load() {
+		if time.Now().After(deadline) {
+			t.Fatal("replica not evicted")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if got := readName(t, db); got != "primary" {
+		t.Fatalf("read after eviction went to %s, want primary", got)
+	}
+}
+
+func TestIsReadQuery(t *testing.T) {
+	for sql, want := range map[string]bool{
+		"SELECT * FROM users":                         true,
+		"  select 1":                                  true,
+		"WITH t AS (SELECT 1) SELECT * FROM t":        true,
+		"SHOW TABLES":                                 true,
+		"SELECT * FROM users FOR UPDATE":              false,
+		"select * from users for share":               false,
+		"SELECT * FROM users LOCK IN SHARE MODE":      false,
+		"UPDATE users SET name = 'a'":                 false,
+		"INSERT INTO users (name) VALUES ('select')":  false,
+		"DELETE FROM users WHERE name LIKE 'select%'": false,
+	} {
+		if got := isReadQuery(sql); got != want {
+			t.Errorf("isReadQuery(%q) = %v, want %v", sql, got, want)
+		}
+	}
+}
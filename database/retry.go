@@ -0,0 +1,114 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/shrimps80/go-service-utils/internal/backoff"
+	"gorm.io/gorm"
+)
+
+// RetryConfig 启动时连接数据库的重试配置。
+// MaxAttempts 与 MaxWait 均为 0 时不重试；任一达到上限即停止重试
+type RetryConfig struct {
+	MaxAttempts     int           // 最大尝试次数（含首次），0 表示仅受 MaxWait 限制
+	InitialInterval time.Duration // 首次重试前的等待时间
+	MaxInterval     time.Duration // 重试等待时间上限
+	Multiplier      float64       // 每次重试等待时间的增长倍数
+	Jitter          float64       // 随机抖动比例（0~1），避免多个实例同时重连
+	MaxWait         time.Duration // 从首次尝试开始的最长等待时间，0 表示仅受 MaxAttempts 限制
+}
+
+// DefaultRetryConfig 返回默认的重试配置：最多等待 1 分钟，适用于容器启动时数据库尚未就绪的场景
+func DefaultRetryConfig() RetryConfig {
+	return RetryConfig{
+		MaxAttempts:     0,
+		InitialInterval: 500 * time.Millisecond,
+		MaxInterval:     10 * time.Second,
+		Multiplier:      2,
+		Jitter:          0.2,
+		MaxWait:         time.Minute,
+	}
+}
+
+// enabled 是否开启重试
+func (r RetryConfig) enabled() bool {
+	return r.MaxAttempts > 1 || r.MaxWait > 0
+}
+
+// interval 计算第 attempt 次重试前的等待时间
+func (r RetryConfig) interval(attempt int) time.Duration {
+	return backoff.Interval(attempt, r.InitialInterval, r.MaxInterval, r.Multiplier, r.Jitter)
+}
+
+// openWithRetry 按重试配置打开数据库连接，每次尝试使用新的驱动实例，失败的连接会被关闭。
+// 连接探测使用 PingContext，ctx 被取消时正在进行的探测随之中止
+func openWithRetry(ctx context.Context, cfg *Config, dsn string, gormCfg *gorm.Config) (*gorm.DB, error) {
+	retry := cfg.Retry
+	start := time.Now()
+
+	for attempt := 1; ; attempt++ {
+		dialector, err := openDialector(cfg.Type, dsn)
+		if err != nil {
+			return nil, err
+		}
+
+		db, err := open(ctx, dialector, gormCfg)
+		if err == nil {
+			return db, nil
+		}
+		if db != nil {
+			if sqlDB, dbErr := db.DB(); dbErr == nil {
+				_ = sqlDB.Close()
+			}
+		}
+
+		if !retry.enabled() || (retry.MaxAttempts > 0 && attempt >= retry.MaxAttempts) {
+			return nil, err
+		}
+
+		wait := retry.interval(attempt - 1)
+		if retry.MaxWait > 0 {
+			remaining := retry.MaxWait - time.Since(start)
+			if remaining <= 0 {
+				return nil, err
+			}
+			if wait > remaining {
+				wait = remaining
+			}
+		}
+
+		if cfg.Logger != nil {
+			cfg.Logger.Warn("database connect failed, retrying",
+				"db", cfg.Name,
+				"attempt", attempt,
+				"wait", wait.String(),
+				"error", err,
+			)
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, errors.Join(err, ctx.Err())
+		}
+	}
+}
+
+// open 打开连接并以 ctx 探测可用性，代替 gorm.Open 内部不可取消的 Ping
+func open(ctx context.Context, dialector gorm.Dialector, gormCfg *gorm.Config) (*gorm.DB, error) {
+	c := *gormCfg
+	c.DisableAutomaticPing = true
+	db, err := gorm.Open(dialector, &c)
+	if err != nil {
+		return db, err
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return db, err
+	}
+	return db, sqlDB.PingContext(ctx)
+}
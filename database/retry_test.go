@@ -0,0 +1,111 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/shrimps80/go-service-utils/logger"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+func TestRetryInterval(t *testing.T) {
+	r := RetryConfig{InitialInterval: 100 * time.Millisecond, MaxInterval: 300 * time.Millisecond, Multiplier: 2}
+	for attempt, want := range []time.Duration{100, 200, 300, 300} {
+		if got := r.interval(attempt); got != want*time.Millisecond {
+			t.Errorf("interval(%d) = %v, want %v", attempt, got, want*time.Millisecond)
+		}
+	}
+
+	// 倍数小于 1 时按 1 处理
+	r.Multiplier = 0.5
+	if got := r.interval(3); got != 100*time.Millisecond {
+		t.Errorf("interval with multiplier < 1 = %v, want 100ms", got)
+	}
+
+	r = RetryConfig{InitialInterval: 100 * time.Millisecond, Multiplier: 2, Jitter: 0.5}
+	for i := 0; i < 100; i++ {
+		if got := r.interval(1); got < 100*time.Millisecond || got > 300*time.Millisecond {
+			t.Fatalf("interval with jitter = %v, want in [100ms, 300ms]", got)
+		}
+	}
+
+	if (RetryConfig{}).enabled() || (RetryConfig{MaxAttempts: 1}).enabled() {
+		t.Error("retry should be disabled without MaxAttempts > 1 or MaxWait")
+	}
+}
+
+// unreachableConfig 返回带重试配置的 SQLite 配置，并记录重试日志
+func unreachableConfig(t *testing.T, retry RetryConfig) (*Config, *observer.ObservedLogs) {
+	t.Helper()
+	core, logs := observer.New(zapcore.WarnLevel)
+	cfg := DefaultConfig()
+	cfg.Type = "sqlite"
+	cfg.Logger = &logger.Logger{Logger: zap.New(core)}
+	cfg.Retry = retry
+	return cfg, logs
+}
+
+// openUnreachable 打开父目录不存在、无法连接的 SQLite 数据库
+func openUnreachable(t *testing.T, ctx context.Context, cfg *Config) error {
+	dsn := filepath.Join(t.TempDir(), "missing", "test.db")
+	_, err := openWithRetry(ctx, cfg, dsn, &gorm.Config{Logger: gormlogger.Discard})
+	return err
+}
+
+func TestOpenWithRetryMaxAttempts(t *testing.T) {
+	cfg, logs := unreachableConfig(t, RetryConfig{MaxAttempts: 3, InitialInterval: time.Millisecond, Multiplier: 2})
+	if err := openUnreachable(t, context.Background(), cfg); err == nil {
+		t.Fatal("expected error")
+	}
+	if n := logs.FilterMessage("database connect failed, retrying").Len(); n != 2 {
+		t.Fatalf("retried %d times, want 2", n)
+	}
+}
+
+func TestOpenWithRetryMaxWait(t *testing.T) {
+	cfg, logs := unreachableConfig(t, RetryConfig{InitialInterval: 20 * time.Millisecond, Multiplier: 1, MaxWait: 100 * time.Millisecond})
+	start := time.Now()
+	if err := openUnreachable(t, context.Background(), cfg); err == nil {
+		t.Fatal("expected error")
+	}
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond || elapsed > time.Second {
+		t.Fatalf("gave up after %v, want about MaxWait", elapsed)
+	}
+	// 最后一次等待被截断到剩余时间内
+	for _, entry := range logs.All() {
+		if wait, _ := time.ParseDuration(entry.ContextMap()["wait"].(string)); wait > 20*time.Millisecond {
+			t.Fatalf("wait %v exceeds interval", wait)
+		}
+	}
+}
+
+func TestOpenWithRetryCancel(t *testing.T) {
+	cfg, _ := unreachableConfig(t, RetryConfig{InitialInterval: time.Hour, Multiplier: 1, MaxWait: time.Hour})
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := openUnreachable(t, ctx, cfg)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want DeadlineExceeded", err)
+	}
+	if time.Since(start) > time.Second {
+		t.Fatal("cancellation did not stop the retry loop")
+	}
+
+	// 已取消的 ctx 使探测立即失败
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	cfg.Retry = RetryConfig{}
+	cfg.DSN = filepath.Join(t.TempDir(), "ok.db")
+	if _, err := openWithRetry(ctx, cfg, cfg.DSN, &gorm.Config{Logger: gormlogger.Discard}); !errors.Is(err, context.Canceled) {
+		t.Fatalf("ping with cancelled ctx = %v, want context.Canceled", err)
+	}
+}
@@ -0,0 +1,173 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"time"
+
+	mysqldriver "github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/mattn/go-sqlite3"
+	"github.com/shrimps80/go-service-utils/internal/backoff"
+	"gorm.io/gorm"
+)
+
+// TxOptions 事务管理器配置
+type TxOptions struct {
+	MaxRetries     int            // 死锁或序列化失败时的最大重试次数，0 表示不重试
+	InitialBackoff time.Duration  // 首次重试前的等待时间
+	MaxBackoff     time.Duration  // 重试等待时间上限
+	SQLOptions     *sql.TxOptions // 隔离级别、只读等事务选项
+}
+
+// DefaultTxOptions 返回默认的事务管理器配置
+func DefaultTxOptions() *TxOptions {
+	return &TxOptions{
+		MaxRetries:     3,
+		InitialBackoff: 20 * time.Millisecond,
+		MaxBackoff:     time.Second,
+	}
+}
+
+type txKey struct{}
+
+// txState 上下文中的事务状态，嵌套事务（保存点）持有独立的状态，提交后将钩子合并到外层
+type txState struct {
+	tx *gorm.DB
+
+	mutex sync.Mutex
+	hooks []func(ctx context.Context)
+}
+
+// addHooks 追加提交后钩子
+func (s *txState) addHooks(hooks ...func(ctx context.Context)) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.hooks = append(s.hooks, hooks...)
+}
+
+// takeHooks 取出并清空提交后钩子
+func (s *txState) takeHooks() []func(ctx context.Context) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	hooks := s.hooks
+	s.hooks = nil
+	return hooks
+}
+
+// TxManager 事务管理器：将当前事务保存在 context 中，使仓储代码通过 DB(ctx) 透明地共享事务
+type TxManager struct {
+	db   *gorm.DB
+	opts *TxOptions
+}
+
+// NewTxManager 创建事务管理器
+func NewTxManager(db *gorm.DB, opts *TxOptions) *TxManager {
+	if opts == nil {
+		opts = DefaultTxOptions()
+	}
+	return &TxManager{db: db, opts: opts}
+}
+
+// FromContext 返回上下文中的当前事务
+func FromContext(ctx context.Context) (*gorm.DB, bool) {
+	s, ok := ctx.Value(txKey{}).(*txState)
+	if !ok {
+		return nil, false
+	}
+	return s.tx.WithContext(ctx), true
+}
+
+// DB 返回当前应使用的数据库会话：上下文中存在事务时返回该事务，否则返回普通会话
+func (m *TxManager) DB(ctx context.Context) *gorm.DB {
+	if tx, ok := FromContext(ctx); ok {
+		return tx
+	}
+	return m.db.WithContext(ctx)
+}
+
+// Do 在事务中执行 fn，fn 返回错误或 panic 时回滚。
+// 上下文中已存在事务时以保存点的方式嵌套执行，嵌套失败只回滚到保存点；
+// 最外层事务遇到死锁或序列化失败时按退避策略整体重试，因此 fn 必须可重复执行。
+func (m *TxManager) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	if parent, ok := ctx.Value(txKey{}).(*txState); ok {
+		return m.nested(ctx, parent, fn)
+	}
+
+	var err error
+	for attempt := 0; ; attempt++ {
+		var state *txState
+		err = m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			state = &txState{tx: tx}
+			return fn(context.WithValue(ctx, txKey{}, state))
+		}, m.opts.SQLOptions)
+
+		if err == nil {
+			// 提交成功后按注册顺序执行钩子
+			for _, hook := range state.takeHooks() {
+				hook(ctx)
+			}
+			return nil
+		}
+
+		if attempt >= m.opts.MaxRetries || !IsRetryable(err) {
+			return err
+		}
+
+		select {
+		case <-time.After(backoff.Exponential(attempt, m.opts.InitialBackoff, m.opts.MaxBackoff)):
+		case <-ctx.Done():
+			return errors.Join(err, ctx.Err())
+		}
+	}
+}
+
+// nested 以保存点的方式执行嵌套事务，成功后将其钩子合并到外层事务
+func (m *TxManager) nested(ctx context.Context, parent *txState, fn func(ctx context.Context) error) error {
+	var state *txState
+	err := parent.tx.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		state = &txState{tx: tx}
+		return fn(context.WithValue(ctx, txKey{}, state))
+	})
+	if err != nil {
+		return err
+	}
+
+	parent.addHooks(state.takeHooks()...)
+	return nil
+}
+
+// AfterCommit 注册在最外层事务提交后执行的钩子，事务回滚时不执行；
+// 上下文中没有事务时立即执行
+func AfterCommit(ctx context.Context, hook func(ctx context.Context)) {
+	s, ok := ctx.Value(txKey{}).(*txState)
+	if !ok {
+		hook(ctx)
+		return
+	}
+	s.addHooks(hook)
+}
+
+// IsRetryable 判断错误是否为可重试的死锁、锁等待超时或序列化失败
+func IsRetryable(err error) bool {
+	var myErr *mysqldriver.MySQLError
+	if errors.As(err, &myErr) {
+		// 1213: 死锁, 1205: 锁等待超时
+		return myErr.Number == 1213 || myErr.Number == 1205
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		// 40001: 序列化失败, 40P01: 死锁
+		return pgErr.Code == "40001" || pgErr.Code == "40P01"
+	}
+
+	var liteErr sqlite3.Error
+	if errors.As(err, &liteErr) {
+		return liteErr.Code == sqlite3.ErrBusy || liteErr.Code == sqlite3.ErrLocked
+	}
+
+	return false
+}
@@ -0,0 +1,120 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+type txItem struct {
+	ID   int
+	Name string
+}
+
+func newTestDB(t *testing.T) *TxManager {
+	t.Helper()
+
+	cfg := DefaultConfig()
+	cfg.Type = "sqlite"
+	cfg.DSN = filepath.Join(t.TempDir(), "test.db")
+	db, err := New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = Close(db) })
+
+	if err := db.AutoMigrate(&txItem{}); err != nil {
+		t.Fatal(err)
+	}
+	return NewTxManager(db, nil)
+}
+
+func countItems(t *testing.T, m *TxManager) int64 {
+	t.Helper()
+	var n int64
+	if err := m.DB(context.Background()).Model(&txItem{}).Count(&n).Error; err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+func TestTxManagerNestedSavepoint(t *testing.T) {
+	m := newTestDB(t)
+	ctx := context.Background()
+
+	var hooks []string
+	err := m.Do(ctx, func(ctx context.Context) error {
+		if err := m.DB(ctx).Create(&txItem{ID: 1, Name: "outer"}).Error; err != nil {
+			return err
+		}
+		AfterCommit(ctx, func(context.Context) { hooks = append(hooks, "outer") })
+
+		// 嵌套事务失败只回滚到保存点，其钩子被丢弃
+		inner := m.Do(ctx, func(ctx context.Context) error {
+			m.DB(ctx).Create(&txItem{ID: 2, Name: "inner"})
+			AfterCommit(ctx, func(context.Context) { hooks = append(hooks, "rolled back") })
+			return errors.New("inner failed")
+		})
+		if inner == nil {
+			t.Error("expected inner error")
+		}
+
+		return m.Do(ctx, func(ctx context.Context) error {
+			AfterCommit(ctx, func(context.Context) { hooks = append(hooks, "nested") })
+			return m.DB(ctx).Create(&txItem{ID: 3, Name: "nested"}).Error
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n := countItems(t, m); n != 2 {
+		t.Errorf("expected 2 items, got %d", n)
+	}
+	if len(hooks) != 2 || hooks[0] != "outer" || hooks[1] != "nested" {
+		t.Errorf("unexpected hooks: %v", hooks)
+	}
+}
+
+func TestTxManagerRollbackSkipsHooks(t *testing.T) {
+	m := newTestDB(t)
+
+	called := false
+	err := m.Do(context.Background(), func(ctx context.Context) error {
+		m.DB(ctx).Create(&txItem{ID: 1})
+		AfterCommit(ctx, func(context.Context) { called = true })
+		return errors.New("failed")
+	})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if called {
+		t.Error("hook should not run after rollback")
+	}
+	if n := countItems(t, m); n != 0 {
+		t.Errorf("expected rollback, got %d items", n)
+	}
+}
+
+func TestTxManagerRetry(t *testing.T) {
+	m := newTestDB(t)
+	m.opts.InitialBackoff = 0
+
+	attempts := 0
+	err := m.Do(context.Background(), func(ctx context.Context) error {
+		attempts++
+		if attempts < 3 {
+			return sqlite3.Error{Code: sqlite3.ErrBusy}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if attempts != 3 {
+		t.Errorf("expected 3 attempts, got %d", attempts)
+	}
+}
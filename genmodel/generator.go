@@ -3,23 +3,52 @@ package genmodel
 import (
 	"database/sql"
 	"fmt"
-	"os"
-	"path/filepath"
+	"path"
 	"strings"
-	"text/template"
 
 	"gorm.io/gorm"
 )
 
 type Config struct {
-	DB          *gorm.DB
-	OutputPath  string
-	PackageName string
-	Template    string
+	DB           *gorm.DB
+	OutputPath   string
+	PackageName  string
+	Template     string
+	Introspector Introspector // 为空时根据 DB 的方言自动选择
+	Types        TypeOptions  // 类型映射选项
+	JSONCase     string       // json 标签命名风格：snake（默认）、camel、none
+	BindingTags  bool         // 是否根据列约束生成 binding 校验标签
+	Include      []string     // 只生成匹配这些通配符的表，为空时生成全部
+	Exclude      []string     // 跳过匹配这些通配符的表
+	Force        bool         // 是否覆盖被手工修改过的文件
+	Templates    []Template   // 命名模板，为空时只使用 Template 生成模型
+}
+
+// Validate 校验配置：通配符、自定义类型映射的正则与 json 命名风格
+func (c *Config) Validate() error {
+	_, err := c.validate()
+	return err
+}
+
+// validate 校验配置并返回预编译的自定义类型映射
+func (c *Config) validate() ([]typeOverride, error) {
+	switch c.JSONCase {
+	case "", JSONCaseSnake, JSONCaseCamel, JSONCaseNone:
+	default:
+		return nil, fmt.Errorf("genmodel: unknown json case %q", c.JSONCase)
+	}
+	for _, pattern := range append(append([]string{}, c.Include...), c.Exclude...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("genmodel: table pattern %q: %w", pattern, err)
+		}
+	}
+	return compileOverrides(c.Types.Overrides)
 }
 
 type Generator struct {
-	config *Config
+	config       *Config
+	introspector Introspector
+	overrides    []typeOverride // 预编译的自定义类型映射
 }
 
 func NewGenerator(config *Config) *Generator {
@@ -29,46 +58,42 @@ func NewGenerator(config *Config) *Generator {
 	return &Generator{config: config}
 }
 
+// GenerateModel 为表生成所有模板对应的文件
 func (g *Generator) GenerateModel(tableName string) error {
-	columns, err := g.getColumns(tableName)
+	files, err := g.Render(tableName)
 	if err != nil {
 		return err
 	}
-
-	structName := g.toCamelCase(tableName)
-
-	tmplData := struct {
-		PackageName string
-		StructName  string
-		TableName   string
-		Columns     []Column
-		Fields      []string
-	}{
-		PackageName: g.config.PackageName,
-		StructName:  structName,
-		TableName:   tableName,
-		Columns:     columns,
-		Fields:      g.getFieldList(columns),
+	for _, f := range files {
+		if err := g.WriteFile(f); err != nil {
+			return err
+		}
 	}
+	return nil
+}
 
-	tmpl, err := template.New("model").Parse(g.config.Template)
+// Render 按所有模板生成表对应的文件内容（已格式化并删除未使用的导入），不写入磁盘
+func (g *Generator) Render(tableName string) ([]*File, error) {
+	specs, err := g.templates()
 	if err != nil {
-		return err
-	}
-
-	if err := os.MkdirAll(g.config.OutputPath, 0755); err != nil {
-		return err
+		return nil, err
 	}
 
-	// 创建输出文件
-	filePath := filepath.Join(g.config.OutputPath, strings.ToLower(tableName)+".go")
-	file, err := os.Create(filePath)
+	columns, err := g.getColumns(tableName)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	defer file.Close()
+	data := g.templateData(tableName, columns)
 
-	return tmpl.Execute(file, tmplData)
+	files := make([]*File, 0, len(specs))
+	for _, spec := range specs {
+		f, err := g.renderTemplate(spec, specs, data)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, f)
+	}
+	return files, nil
 }
 
 func (g *Generator) getFieldList(columns []Column) []string {
@@ -80,7 +105,7 @@ func (g *Generator) getFieldList(columns []Column) []string {
 }
 
 func (g *Generator) GenerateAllModels() error {
-	tables, err := g.getTables()
+	tables, err := g.Tables()
 	if err != nil {
 		return err
 	}
@@ -95,105 +120,107 @@ func (g *Generator) GenerateAllModels() error {
 }
 
 type Column struct {
-	Field      string
-	Type       string
-	Default    *string
-	Privileges sql.NullString
-	Comment    *string
-	GoField    string
-	GoType     string
-	Collation  *string
-	IsPrimary  bool
+	Field         string
+	Type          string
+	Default       *string
+	Privileges    sql.NullString
+	Comment       *string
+	GoField       string
+	GoType        string
+	Collation     *string
+	IsPrimary     bool
+	Nullable      bool
+	AutoIncrement bool
+	Size          int    // 字符类型的长度
+	GormTag       string // gorm 标签内容
+	JSONTag       string // json 标签内容
+	BindingTag    string // binding 标签内容
+	Tag           string // 完整的结构体标签（不含反引号）
+
+	imports []string // GoType 需要导入的包
 }
 
-func (g *Generator) getTables() ([]string, error) {
-	var tables []string
-	rows, err := g.config.DB.Raw("SHOW TABLES").Rows()
+// getIntrospector 返回表结构读取实现，未配置时根据 DB 的方言选择；首次调用时校验配置
+func (g *Generator) getIntrospector() (Introspector, error) {
+	if g.introspector == nil {
+		overrides, err := g.config.validate()
+		if err != nil {
+			return nil, err
+		}
+		g.overrides = overrides
+
+		if g.config.Introspector != nil {
+			g.introspector = g.config.Introspector
+		} else {
+			in, err := NewIntrospector(g.config.DB)
+			if err != nil {
+				return nil, err
+			}
+			g.introspector = in
+		}
+	}
+	return g.introspector, nil
+}
+
+// Tables 返回按 Include / Exclude 过滤后的表名
+func (g *Generator) Tables() ([]string, error) {
+	in, err := g.getIntrospector()
+	if err != nil {
+		return nil, err
+	}
+	tables, err := in.Tables()
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
 
-	for rows.Next() {
-		var table string
-		if err := rows.Scan(&table); err != nil {
-			return nil, err
+	filtered := tables[:0]
+	for _, table := range tables {
+		if len(g.config.Include) > 0 && !matchAny(g.config.Include, table) {
+			continue
 		}
-		tables = append(tables, table)
+		if matchAny(g.config.Exclude, table) {
+			continue
+		}
+		filtered = append(filtered, table)
 	}
+	return filtered, nil
+}
 
-	return tables, nil
+// matchAny 判断表名是否匹配任一通配符
+func matchAny(patterns []string, table string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, table); ok {
+			return true
+		}
+	}
+	return false
 }
 
 func (g *Generator) getColumns(tableName string) ([]Column, error) {
-	rows, err := g.config.DB.Raw("SHOW FULL COLUMNS FROM " + tableName).Rows()
+	in, err := g.getIntrospector()
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
-
-	var columns []Column
-	for rows.Next() {
-		var col Column
-		var (
-			collation  sql.NullString
-			null       string
-			key        string
-			defaultVal sql.NullString
-			extra      string
-			comment    sql.NullString
-		)
-
-		err := rows.Scan(
-			&col.Field,
-			&col.Type,
-			&collation,
-			&null,
-			&key,
-			&defaultVal,
-			&extra,
-			&col.Privileges,
-			&comment,
-		)
-		if err != nil {
-			return nil, err
-		}
-
-		if defaultVal.Valid {
-			col.Default = &defaultVal.String
-		}
 
-		if comment.Valid {
-			col.Comment = &comment.String
-		}
+	columns, err := in.Columns(tableName)
+	if err != nil {
+		return nil, err
+	}
+	indexes, err := in.Indexes(tableName)
+	if err != nil {
+		return nil, err
+	}
 
-		col.IsPrimary = key == "PRI"
+	for i := range columns {
+		col := &columns[i]
 		col.GoField = g.toCamelCase(col.Field)
-		col.GoType = g.mysqlTypeToGo(col.Type)
-		columns = append(columns, col)
+		col.GoType, col.imports = g.resolveType(in, tableName, *col)
+		col.Size = columnSize(col.Type)
+		g.buildTags(col, indexes)
 	}
-
 	return columns, nil
 }
 
-func (g *Generator) mysqlTypeToGo(mysqlType string) string {
-	mysqlType = strings.ToLower(mysqlType)
-	switch {
-	case strings.Contains(mysqlType, "bigint"):
-		return "uint64"
-	case strings.Contains(mysqlType, "int"):
-		return "int"
-	case strings.Contains(mysqlType, "decimal"), strings.Contains(mysqlType, "numeric"), strings.Contains(mysqlType, "float"), strings.Contains(mysqlType, "double"):
-		return "float64"
-	case strings.Contains(mysqlType, "datetime"), strings.Contains(mysqlType, "timestamp"):
-		return "time.Time"
-	case strings.Contains(mysqlType, "bool"):
-		return "bool"
-	default:
-		return "string"
-	}
-}
-
 func (g *Generator) toCamelCase(s string) string {
 	parts := strings.Split(s, "_")
 	for i := range parts {
@@ -201,28 +228,3 @@ func (g *Generator) toCamelCase(s string) string {
 	}
 	return strings.Join(parts, "")
 }
-
-const defaultTemplate = `package {{.PackageName}}
-
-import (
-	"time"
-)
-
-type {{.StructName}} struct {
-	{{range .Columns -}}
-	{{.GoField}} {{.GoType}} ` + "`gorm:\"column:{{.Field}}{{if .IsPrimary}};primaryKey{{end}}{{if .Comment}};comment:{{.Comment}}{{end}}\"`" + `
-	{{end}}
-}
-
-func ({{.StructName}}) TableName() string {
-	return "{{.TableName}}"
-}
-
-func ({{.StructName}}) GetFields() []string {
-	return []string{
-		{{range $field := .Fields -}}
-		"{{$field}}",
-		{{end}}
-	}
-}
-`
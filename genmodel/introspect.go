@@ -0,0 +1,58 @@
+package genmodel
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// Introspector 读取数据库表结构，不同数据库方言分别实现
+type Introspector interface {
+	// Tables 返回当前库（schema）中的所有表名
+	Tables() ([]string, error)
+	// Columns 按定义顺序返回表的列信息
+	Columns(table string) ([]Column, error)
+	// Indexes 返回表的索引（含主键）
+	Indexes(table string) ([]Index, error)
+	// GoType 返回列的基础 Go 类型（不考虑可空），定点数与 JSON 列分别返回 TypeDecimal 与 TypeJSON，
+	// 由 Generator 按 TypeOptions 替换为具体类型
+	GoType(col Column) string
+}
+
+// NewIntrospector 根据 gorm 驱动的方言名称选择表结构读取实现
+func NewIntrospector(db *gorm.DB) (Introspector, error) {
+	switch name := db.Dialector.Name(); name {
+	case "mysql":
+		return &mysqlIntrospector{db: db}, nil
+	case "postgres":
+		return &postgresIntrospector{db: db}, nil
+	case "sqlite":
+		return &sqliteIntrospector{db: db}, nil
+	default:
+		return nil, fmt.Errorf("genmodel: unsupported dialect %q", name)
+	}
+}
+
+// groupIndexes 将按索引名与列顺序排列的行合并为索引
+func groupIndexes(rows []indexRow) []Index {
+	var indexes []Index
+	pos := make(map[string]int)
+	for _, r := range rows {
+		i, ok := pos[r.Name]
+		if !ok {
+			i = len(indexes)
+			pos[r.Name] = i
+			indexes = append(indexes, Index{Name: r.Name, Unique: r.Unique, Primary: r.Primary})
+		}
+		indexes[i].Columns = append(indexes[i].Columns, r.Column)
+	}
+	return indexes
+}
+
+// indexRow 索引中的一列
+type indexRow struct {
+	Name    string
+	Column  string
+	Unique  bool
+	Primary bool
+}
@@ -0,0 +1,142 @@
+package genmodel
+
+import (
+	"database/sql"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// mysqlIntrospector 通过 SHOW TABLES / SHOW FULL COLUMNS 读取 MySQL 表结构
+type mysqlIntrospector struct {
+	db *gorm.DB
+}
+
+func (m *mysqlIntrospector) Tables() ([]string, error) {
+	var tables []string
+	rows, err := m.db.Raw("SHOW TABLES").Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var table string
+		if err := rows.Scan(&table); err != nil {
+			return nil, err
+		}
+		tables = append(tables, table)
+	}
+
+	return tables, rows.Err()
+}
+
+func (m *mysqlIntrospector) Columns(table string) ([]Column, error) {
+	rows, err := m.db.Raw("SHOW FULL COLUMNS FROM " + m.db.Statement.Quote(table)).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var columns []Column
+	for rows.Next() {
+		var col Column
+		var (
+			collation  sql.NullString
+			null       string
+			key        string
+			defaultVal sql.NullString
+			extra      string
+			comment    sql.NullString
+		)
+
+		err := rows.Scan(
+			&col.Field,
+			&col.Type,
+			&collation,
+			&null,
+			&key,
+			&defaultVal,
+			&extra,
+			&col.Privileges,
+			&comment,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		if collation.Valid {
+			col.Collation = &collation.String
+		}
+		if defaultVal.Valid {
+			col.Default = &defaultVal.String
+		}
+		if comment.Valid && comment.String != "" {
+			col.Comment = &comment.String
+		}
+
+		col.IsPrimary = key == "PRI"
+		col.Nullable = null == "YES"
+		col.AutoIncrement = strings.Contains(strings.ToLower(extra), "auto_increment")
+		columns = append(columns, col)
+	}
+
+	return columns, rows.Err()
+}
+
+func (m *mysqlIntrospector) Indexes(table string) ([]Index, error) {
+	var rows []indexRow
+	err := m.db.Raw(`SELECT index_name AS name, column_name AS `+"`column`"+`,
+			non_unique = 0 AS `+"`unique`"+`, index_name = 'PRIMARY' AS `+"`primary`"+`
+		FROM information_schema.statistics
+		WHERE table_schema = DATABASE() AND table_name = ?
+		ORDER BY index_name, seq_in_index`, table).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	return groupIndexes(rows), nil
+}
+
+func (m *mysqlIntrospector) GoType(col Column) string {
+	base, args, unsigned := parseSQLType(col.Type)
+
+	intType := func(signed string) string {
+		if unsigned {
+			return "u" + signed
+		}
+		return signed
+	}
+
+	switch base {
+	case "tinyint":
+		if args == "1" {
+			return "bool"
+		}
+		return intType("int8")
+	case "smallint", "year":
+		return intType("int16")
+	case "mediumint", "int", "integer":
+		return intType("int32")
+	case "bigint":
+		return intType("int64")
+	case "bit":
+		// 驱动以 []byte 返回 bit 列（bit(1) 为 []byte{1}），无法直接扫描到 bool
+		return "[]byte"
+	case "bool", "boolean":
+		return "bool"
+	case "decimal", "numeric", "dec", "fixed":
+		return TypeDecimal
+	case "float":
+		return "float32"
+	case "double", "real":
+		return "float64"
+	case "date", "datetime", "timestamp":
+		return "time.Time"
+	case "json":
+		return TypeJSON
+	case "binary", "varbinary", "tinyblob", "blob", "mediumblob", "longblob":
+		return "[]byte"
+	default:
+		return "string"
+	}
+}
@@ -0,0 +1,138 @@
+package genmodel
+
+import (
+	"database/sql"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// postgresIntrospector 通过 information_schema 与 pg_catalog 读取 PostgreSQL 当前 schema 的表结构
+type postgresIntrospector struct {
+	db *gorm.DB
+}
+
+func (p *postgresIntrospector) Tables() ([]string, error) {
+	var tables []string
+	err := p.db.Raw(`SELECT table_name FROM information_schema.tables
+		WHERE table_schema = current_schema() AND table_type = 'BASE TABLE'
+		ORDER BY table_name`).Scan(&tables).Error
+	return tables, err
+}
+
+func (p *postgresIntrospector) Columns(table string) ([]Column, error) {
+	rows, err := p.db.Raw(`SELECT
+			a.attname,
+			format_type(a.atttypid, a.atttypmod),
+			NOT a.attnotnull,
+			pg_get_expr(d.adbin, d.adrelid),
+			col_description(a.attrelid, a.attnum),
+			coll.collname,
+			EXISTS (
+				SELECT 1 FROM pg_index i
+				WHERE i.indrelid = a.attrelid AND i.indisprimary AND a.attnum = ANY(i.indkey)
+			),
+			a.attidentity <> ''
+		FROM pg_attribute a
+		JOIN pg_class c ON c.oid = a.attrelid
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		LEFT JOIN pg_attrdef d ON d.adrelid = a.attrelid AND d.adnum = a.attnum
+		LEFT JOIN pg_collation coll ON coll.oid = a.attcollation AND a.attcollation <> 0
+		WHERE c.relname = ? AND n.nspname = current_schema() AND a.attnum > 0 AND NOT a.attisdropped
+		ORDER BY a.attnum`, table).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var columns []Column
+	for rows.Next() {
+		var col Column
+		var (
+			defaultVal sql.NullString
+			comment    sql.NullString
+			collation  sql.NullString
+			identity   bool
+		)
+
+		err := rows.Scan(
+			&col.Field,
+			&col.Type,
+			&col.Nullable,
+			&defaultVal,
+			&comment,
+			&collation,
+			&col.IsPrimary,
+			&identity,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		if defaultVal.Valid {
+			col.Default = &defaultVal.String
+		}
+		if comment.Valid && comment.String != "" {
+			col.Comment = &comment.String
+		}
+		if collation.Valid {
+			col.Collation = &collation.String
+		}
+
+		// serial 列的默认值为 nextval(...)
+		col.AutoIncrement = identity || (defaultVal.Valid && strings.HasPrefix(defaultVal.String, "nextval("))
+		columns = append(columns, col)
+	}
+
+	return columns, rows.Err()
+}
+
+func (p *postgresIntrospector) Indexes(table string) ([]Index, error) {
+	var rows []indexRow
+	err := p.db.Raw(`SELECT i.relname AS name, a.attname AS column,
+			ix.indisunique AS unique, ix.indisprimary AS primary
+		FROM pg_index ix
+		JOIN pg_class t ON t.oid = ix.indrelid
+		JOIN pg_class i ON i.oid = ix.indexrelid
+		JOIN pg_namespace n ON n.oid = t.relnamespace
+		JOIN LATERAL unnest(ix.indkey) WITH ORDINALITY AS k(attnum, ord) ON true
+		JOIN pg_attribute a ON a.attrelid = t.oid AND a.attnum = k.attnum
+		WHERE t.relname = ? AND n.nspname = current_schema()
+		ORDER BY i.relname, k.ord`, table).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	return groupIndexes(rows), nil
+}
+
+func (p *postgresIntrospector) GoType(col Column) string {
+	if strings.HasSuffix(col.Type, "[]") {
+		return "string"
+	}
+
+	base, _, _ := parseSQLType(col.Type)
+	switch {
+	case base == "smallint" || base == "smallserial":
+		return "int16"
+	case base == "integer" || base == "serial":
+		return "int32"
+	case base == "bigint" || base == "bigserial":
+		return "int64"
+	case base == "real":
+		return "float32"
+	case base == "double":
+		return "float64"
+	case base == "numeric" || base == "decimal":
+		return TypeDecimal
+	case base == "boolean":
+		return "bool"
+	case base == "json" || base == "jsonb":
+		return TypeJSON
+	case base == "bytea":
+		return "[]byte"
+	case base == "date" || strings.HasPrefix(base, "timestamp"):
+		return "time.Time"
+	default:
+		return "string"
+	}
+}
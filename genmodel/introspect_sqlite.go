@@ -0,0 +1,108 @@
+package genmodel
+
+import (
+	"database/sql"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// sqliteIntrospector 通过 sqlite_master 与 PRAGMA table_info 读取 SQLite 表结构
+type sqliteIntrospector struct {
+	db *gorm.DB
+}
+
+func (s *sqliteIntrospector) Tables() ([]string, error) {
+	var tables []string
+	err := s.db.Raw(`SELECT name FROM sqlite_master
+		WHERE type = 'table' AND name NOT LIKE 'sqlite_%'
+		ORDER BY name`).Scan(&tables).Error
+	return tables, err
+}
+
+func (s *sqliteIntrospector) Columns(table string) ([]Column, error) {
+	rows, err := s.db.Raw(`SELECT name, type, "notnull", dflt_value, pk FROM pragma_table_info(?) ORDER BY cid`, table).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var (
+		columns []Column
+		pkCount int
+	)
+	for rows.Next() {
+		var col Column
+		var (
+			notNull    bool
+			defaultVal sql.NullString
+			pk         int
+		)
+
+		if err := rows.Scan(&col.Field, &col.Type, &notNull, &defaultVal, &pk); err != nil {
+			return nil, err
+		}
+
+		if defaultVal.Valid {
+			col.Default = &defaultVal.String
+		}
+		col.IsPrimary = pk > 0
+		col.Nullable = !notNull && !col.IsPrimary
+		if col.IsPrimary {
+			pkCount++
+		}
+		columns = append(columns, col)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// 单列 INTEGER 主键是 rowid 的别名，插入时自动分配
+	if pkCount == 1 {
+		for i := range columns {
+			if columns[i].IsPrimary && strings.EqualFold(columns[i].Type, "integer") {
+				columns[i].AutoIncrement = true
+			}
+		}
+	}
+
+	return columns, nil
+}
+
+func (s *sqliteIntrospector) Indexes(table string) ([]Index, error) {
+	var rows []indexRow
+	err := s.db.Raw(`SELECT il.name AS name, ii.name AS "column",
+			il."unique" AS "unique", il.origin = 'pk' AS "primary"
+		FROM pragma_index_list(?) il
+		JOIN pragma_index_info(il.name) ii
+		ORDER BY il.name, ii.seqno`, table).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	return groupIndexes(rows), nil
+}
+
+// GoType 按 SQLite 的类型亲和性规则映射
+func (s *sqliteIntrospector) GoType(col Column) string {
+	base, args, _ := parseSQLType(col.Type)
+	switch {
+	case strings.Contains(base, "bool"), base == "tinyint" && args == "1":
+		return "bool"
+	case strings.Contains(base, "int"):
+		return "int64"
+	case base == "decimal" || base == "numeric":
+		return TypeDecimal
+	case base == "json":
+		return TypeJSON
+	case strings.Contains(base, "date"), strings.Contains(base, "time"):
+		return "time.Time"
+	case strings.Contains(base, "char"), strings.Contains(base, "clob"), strings.Contains(base, "text"):
+		return "string"
+	case strings.Contains(base, "blob"):
+		return "[]byte"
+	case strings.Contains(base, "real"), strings.Contains(base, "floa"), strings.Contains(base, "doub"):
+		return "float64"
+	default:
+		return "string"
+	}
+}
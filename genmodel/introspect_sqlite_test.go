@@ -0,0 +1,137 @@
+package genmodel
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+func newSQLiteIntrospector(t *testing.T, ddl ...string) Introspector {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{Logger: gormlogger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			_ = sqlDB.Close()
+		}
+	})
+	for _, stmt := range ddl {
+		if err := db.Exec(stmt).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	in, err := NewIntrospector(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return in
+}
+
+func TestSQLiteIntrospector(t *testing.T) {
+	in := newSQLiteIntrospector(t,
+		`CREATE TABLE users (
+			id INTEGER PRIMARY KEY,
+			tenant_id INTEGER NOT NULL,
+			email VARCHAR(64) NOT NULL,
+			nickname TEXT,
+			status VARCHAR(16) NOT NULL DEFAULT 'active',
+			balance DECIMAL(10,2),
+			verified BOOLEAN NOT NULL DEFAULT 0,
+			avatar BLOB,
+			created_at DATETIME
+		)`,
+		`CREATE UNIQUE INDEX uk_tenant_email ON users (tenant_id, email)`,
+		`CREATE INDEX idx_status ON users (status)`,
+		`CREATE TABLE user_roles (
+			user_id INTEGER NOT NULL,
+			role_id INTEGER NOT NULL,
+			PRIMARY KEY (user_id, role_id)
+		)`,
+	)
+
+	tables, err := in.Tables()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"user_roles", "users"}; !reflect.DeepEqual(tables, want) {
+		t.Fatalf("Tables = %v, want %v", tables, want)
+	}
+
+	columns, err := in.Columns("users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	byName := make(map[string]Column, len(columns))
+	var names []string
+	for _, col := range columns {
+		byName[col.Field] = col
+		names = append(names, col.Field)
+	}
+	if want := []string{"id", "tenant_id", "email", "nickname", "status", "balance", "verified", "avatar", "created_at"}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("columns = %v, want %v", names, want)
+	}
+	if id := byName["id"]; !id.IsPrimary || !id.AutoIncrement || id.Nullable {
+		t.Errorf("id = %+v, want a non-null auto increment primary key", id)
+	}
+	if email := byName["email"]; email.Nullable || email.Type != "VARCHAR(64)" {
+		t.Errorf("email = %+v", email)
+	}
+	if !byName["nickname"].Nullable {
+		t.Error("nickname should be nullable")
+	}
+	if status := byName["status"]; status.Default == nil || *status.Default != "'active'" {
+		t.Errorf("status default = %v", status.Default)
+	}
+
+	for field, want := range map[string]string{
+		"id":         "int64",
+		"email":      "string",
+		"nickname":   "string",
+		"balance":    TypeDecimal,
+		"verified":   "bool",
+		"avatar":     "[]byte",
+		"created_at": "time.Time",
+	} {
+		if got := in.GoType(byName[field]); got != want {
+			t.Errorf("GoType(%s) = %q, want %q", field, got, want)
+		}
+	}
+
+	indexes, err := in.Indexes("users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Index{
+		{Name: "idx_status", Columns: []string{"status"}},
+		{Name: "uk_tenant_email", Columns: []string{"tenant_id", "email"}, Unique: true},
+	}
+	if !reflect.DeepEqual(indexes, want) {
+		t.Fatalf("Indexes = %+v, want %+v", indexes, want)
+	}
+
+	// 复合主键的 INTEGER 列不是 rowid 别名，不自增
+	columns, err = in.Columns("user_roles")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, col := range columns {
+		if !col.IsPrimary || col.AutoIncrement {
+			t.Errorf("user_roles.%s = %+v, want a non auto increment primary key", col.Field, col)
+		}
+	}
+	indexes, err = in.Indexes("user_roles")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(indexes) != 1 || !indexes[0].Primary || !reflect.DeepEqual(indexes[0].Columns, []string{"user_id", "role_id"}) {
+		t.Fatalf("user_roles indexes = %+v", indexes)
+	}
+}
@@ -0,0 +1,187 @@
+package genmodel
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ErrHandEdited 目标文件被手工修改过（或不是生成的文件），未开启 Force 时拒绝覆盖
+var ErrHandEdited = errors.New("genmodel: file was edited by hand")
+
+// 生成文件头，校验和用于识别手工修改
+const (
+	generatedHeader = "// Code generated by genmodel. DO NOT EDIT."
+	checksumPrefix  = "// genmodel-checksum: sha256:"
+)
+
+// FileState 输出文件的当前状态
+type FileState int
+
+const (
+	FileMissing   FileState = iota // 文件不存在
+	FileGenerated                  // 生成后未被修改
+	FileModified                   // 生成后被手工修改
+	FileManual                     // 不是生成的文件
+)
+
+// File 生成的文件
+type File struct {
+	Table   string
+	Path    string
+	Content []byte
+}
+
+// InspectFile 读取已有文件并判断其状态
+func InspectFile(filePath string) (FileState, []byte, error) {
+	content, err := os.ReadFile(filePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return FileMissing, nil, nil
+	}
+	if err != nil {
+		return FileMissing, nil, err
+	}
+
+	header, rest, ok := bytes.Cut(content, []byte("\n"))
+	if !ok || string(header) != generatedHeader {
+		return FileManual, content, nil
+	}
+	line, body, ok := bytes.Cut(rest, []byte("\n"))
+	if !ok || !bytes.HasPrefix(line, []byte(checksumPrefix)) {
+		return FileModified, content, nil
+	}
+	body = bytes.TrimPrefix(body, []byte("\n"))
+	if string(line[len(checksumPrefix):]) != checksum(body) {
+		return FileModified, content, nil
+	}
+	return FileGenerated, content, nil
+}
+
+// WriteFile 写入生成的文件，目标文件被手工修改过且未开启 Force 时返回 ErrHandEdited
+func (g *Generator) WriteFile(f *File) error {
+	state, old, err := InspectFile(f.Path)
+	if err != nil {
+		return err
+	}
+	if (state == FileModified || state == FileManual) && !g.config.Force {
+		return fmt.Errorf("%w: %s", ErrHandEdited, f.Path)
+	}
+	if bytes.Equal(old, f.Content) {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(f.Path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(f.Path, f.Content, 0644)
+}
+
+// finalize 删除未使用的导入、格式化代码并加上带校验和的文件头
+func finalize(src []byte) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("genmodel: generated code is invalid: %w", err)
+	}
+	pruneImports(file)
+
+	var buf bytes.Buffer
+	if err := format.Node(&buf, fset, file); err != nil {
+		return nil, err
+	}
+	body := buf.Bytes()
+
+	out := make([]byte, 0, len(body)+128)
+	out = append(out, generatedHeader+"\n"...)
+	// 文件头与 package 之间空一行，避免成为包文档
+	out = append(out, checksumPrefix+checksum(body)+"\n\n"...)
+	return append(out, body...), nil
+}
+
+// checksum 计算文件内容的校验和
+func checksum(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
+
+// pruneImports 删除文件中未被引用的导入
+func pruneImports(file *ast.File) {
+	used := make(map[string]bool)
+	ast.Inspect(file, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if ident, ok := sel.X.(*ast.Ident); ok {
+				used[ident.Name] = true
+			}
+		}
+		return true
+	})
+
+	decls := file.Decls[:0]
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.IMPORT {
+			decls = append(decls, decl)
+			continue
+		}
+
+		specs := gen.Specs[:0]
+		for _, spec := range gen.Specs {
+			imp := spec.(*ast.ImportSpec)
+			name := importName(imp)
+			if name == "_" || name == "." || used[name] {
+				specs = append(specs, spec)
+			}
+		}
+		if len(specs) == 0 {
+			continue
+		}
+		gen.Specs = specs
+		if len(specs) == 1 {
+			gen.Lparen = token.NoPos
+		}
+		decls = append(decls, gen)
+	}
+	file.Decls = decls
+
+	imports := file.Imports[:0]
+	for _, imp := range file.Imports {
+		if name := importName(imp); name == "_" || name == "." || used[name] {
+			imports = append(imports, imp)
+		}
+	}
+	file.Imports = imports
+}
+
+var versionSuffix = regexp.MustCompile(`^v[0-9]+$`)
+
+// importName 推断导入在代码中使用的包名
+func importName(imp *ast.ImportSpec) string {
+	if imp.Name != nil {
+		return imp.Name.Name
+	}
+
+	p, _ := strconv.Unquote(imp.Path.Value)
+	name := path.Base(p)
+	if versionSuffix.MatchString(name) && path.Dir(p) != "." {
+		name = path.Base(path.Dir(p))
+	}
+	if i := strings.Index(name, ".v"); i > 0 {
+		name = name[:i]
+	}
+	if i := strings.LastIndex(name, "-"); i >= 0 {
+		name = name[i+1:]
+	}
+	return name
+}
@@ -0,0 +1,67 @@
+package genmodel
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFinalizeAndInspect(t *testing.T) {
+	src := []byte(`package model
+import (
+	"time"
+	"encoding/json"
+	"github.com/jackc/pgx/v5"
+)
+type A struct {
+Meta json.RawMessage
+Conn *pgx.Conn
+}
+`)
+	out, err := finalize(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(out, []byte(`"time"`)) {
+		t.Errorf("unused import not pruned:\n%s", out)
+	}
+	if !bytes.Contains(out, []byte(`"encoding/json"`)) || !bytes.Contains(out, []byte(`"github.com/jackc/pgx/v5"`)) {
+		t.Errorf("used import pruned:\n%s", out)
+	}
+	if !bytes.Contains(out, []byte("\tMeta json.RawMessage\n")) {
+		t.Errorf("output not formatted:\n%s", out)
+	}
+
+	path := filepath.Join(t.TempDir(), "a.go")
+	if state, _, _ := InspectFile(path); state != FileMissing {
+		t.Errorf("state = %v, want FileMissing", state)
+	}
+
+	g := NewGenerator(&Config{})
+	f := &File{Path: path, Content: out}
+	if err := g.WriteFile(f); err != nil {
+		t.Fatal(err)
+	}
+	if state, _, _ := InspectFile(path); state != FileGenerated {
+		t.Errorf("state = %v, want FileGenerated", state)
+	}
+
+	if err := os.WriteFile(path, append(out, "// edited\n"...), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if state, _, _ := InspectFile(path); state != FileModified {
+		t.Errorf("state = %v, want FileModified", state)
+	}
+	if err := g.WriteFile(f); err == nil {
+		t.Error("expected ErrHandEdited")
+	}
+
+	g.config.Force = true
+	if err := g.WriteFile(f); err != nil {
+		t.Fatal(err)
+	}
+	if state, _, _ := InspectFile(path); state != FileGenerated {
+		t.Errorf("state = %v, want FileGenerated after force", state)
+	}
+}
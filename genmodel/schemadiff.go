@@ -0,0 +1,543 @@
+package genmodel
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// 差异类型：以数据库为准，added 表示数据库中有而模型中没有
+const (
+	ChangeAdded   = "added"   // 数据库中新增
+	ChangeRemoved = "removed" // 数据库中已不存在
+	ChangeChanged = "changed" // 两边都有但定义不同
+)
+
+// Model 从 Go 源文件中解析出的模型
+type Model struct {
+	Struct  string       // 结构体名
+	Table   string       // TableName 方法返回的表名
+	File    string       // 所在文件
+	Fields  []ModelField // 映射到列的字段
+	Indexes []Index      // 根据 gorm 标签还原的索引（不含主键）
+}
+
+// ModelField 模型中映射到列的字段
+type ModelField struct {
+	Name   string // 字段名
+	Column string // 列名，标签中没有 column 时按字段名转为蛇形
+	GoType string // 字段的 Go 类型
+	DBType string // gorm 标签中的 type，未设置时为空
+}
+
+// SchemaDiff 模型与数据库表结构之间的差异
+type SchemaDiff struct {
+	Tables []TableDiff `json:"tables"`
+}
+
+// TableDiff 一张表的差异，Change 非空时表示整张表新增或已删除
+type TableDiff struct {
+	Table   string       `json:"table"`
+	Struct  string       `json:"struct,omitempty"`
+	File    string       `json:"file,omitempty"`
+	Change  string       `json:"change,omitempty"`
+	Columns []ColumnDiff `json:"columns,omitempty"`
+	Indexes []IndexDiff  `json:"indexes,omitempty"`
+}
+
+// ColumnDiff 一列的差异
+type ColumnDiff struct {
+	Column      string `json:"column"`
+	Change      string `json:"change"`
+	ModelType   string `json:"model_type,omitempty"`
+	DBType      string `json:"db_type,omitempty"`
+	ModelGoType string `json:"model_go_type,omitempty"`
+	DBGoType    string `json:"db_go_type,omitempty"`
+}
+
+// IndexDiff 一个索引的差异
+type IndexDiff struct {
+	Index        string   `json:"index"`
+	Change       string   `json:"change"`
+	ModelColumns []string `json:"model_columns,omitempty"`
+	DBColumns    []string `json:"db_columns,omitempty"`
+	ModelUnique  bool     `json:"model_unique,omitempty"`
+	DBUnique     bool     `json:"db_unique,omitempty"`
+}
+
+// HasChanges 是否存在差异
+func (d *SchemaDiff) HasChanges() bool {
+	return len(d.Tables) > 0
+}
+
+// WriteJSON 以 JSON 格式输出差异
+func (d *SchemaDiff) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(d)
+}
+
+// WriteText 以便于阅读的文本格式输出差异：+ 数据库新增，- 数据库已删除，~ 定义变化
+func (d *SchemaDiff) WriteText(w io.Writer) error {
+	var sb strings.Builder
+	for _, t := range d.Tables {
+		switch t.Change {
+		case ChangeAdded:
+			fmt.Fprintf(&sb, "+ table %s (no model)\n", t.Table)
+			continue
+		case ChangeRemoved:
+			fmt.Fprintf(&sb, "- table %s (%s in %s)\n", t.Table, t.Struct, t.File)
+			continue
+		}
+
+		fmt.Fprintf(&sb, "~ table %s (%s in %s)\n", t.Table, t.Struct, t.File)
+		for _, c := range t.Columns {
+			switch c.Change {
+			case ChangeAdded:
+				fmt.Fprintf(&sb, "    + column %s %s (%s)\n", c.Column, c.DBType, c.DBGoType)
+			case ChangeRemoved:
+				fmt.Fprintf(&sb, "    - column %s %s\n", c.Column, c.ModelGoType)
+			default:
+				fmt.Fprintf(&sb, "    ~ column %s:", c.Column)
+				if c.ModelType != c.DBType {
+					fmt.Fprintf(&sb, " type %s -> %s", c.ModelType, c.DBType)
+				}
+				if c.ModelGoType != c.DBGoType {
+					fmt.Fprintf(&sb, " go %s -> %s", c.ModelGoType, c.DBGoType)
+				}
+				sb.WriteString("\n")
+			}
+		}
+		for _, i := range t.Indexes {
+			switch i.Change {
+			case ChangeAdded:
+				fmt.Fprintf(&sb, "    + index %s %s\n", i.Index, indexDesc(i.DBColumns, i.DBUnique))
+			case ChangeRemoved:
+				fmt.Fprintf(&sb, "    - index %s %s\n", i.Index, indexDesc(i.ModelColumns, i.ModelUnique))
+			default:
+				fmt.Fprintf(&sb, "    ~ index %s: %s -> %s\n", i.Index,
+					indexDesc(i.ModelColumns, i.ModelUnique), indexDesc(i.DBColumns, i.DBUnique))
+			}
+		}
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// indexDesc 索引的文本描述，如 unique(tenant_id,status)
+func indexDesc(columns []string, unique bool) string {
+	desc := "(" + strings.Join(columns, ",") + ")"
+	if unique {
+		desc = "unique" + desc
+	}
+	return desc
+}
+
+// LoadModels 解析目录下的 Go 文件，返回所有带 TableName 方法的结构体
+func LoadModels(dir string) ([]Model, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+
+	fset := token.NewFileSet()
+	structs := make(map[string]*ast.StructType)
+	files := make(map[string]string)
+	tables := make(map[string]string)
+	var order []string
+	for _, p := range paths {
+		if strings.HasSuffix(p, "_test.go") {
+			continue
+		}
+		src, err := os.ReadFile(p)
+		if err != nil {
+			return nil, err
+		}
+		file, err := parser.ParseFile(fset, p, src, 0)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, decl := range file.Decls {
+			switch decl := decl.(type) {
+			case *ast.GenDecl:
+				for _, spec := range decl.Specs {
+					ts, ok := spec.(*ast.TypeSpec)
+					if !ok {
+						continue
+					}
+					st, ok := ts.Type.(*ast.StructType)
+					if !ok {
+						continue
+					}
+					structs[ts.Name.Name], files[ts.Name.Name] = st, p
+					order = append(order, ts.Name.Name)
+				}
+			case *ast.FuncDecl:
+				if recv, table, ok := tableNameMethod(decl); ok {
+					tables[recv] = table
+				}
+			}
+		}
+	}
+
+	var models []Model
+	for _, name := range order {
+		table, ok := tables[name]
+		if !ok {
+			continue
+		}
+		m, err := parseModel(structs[name], table, structs)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		m.Struct, m.File = name, files[name]
+		models = append(models, m)
+	}
+	return models, nil
+}
+
+// tableNameMethod 识别返回字符串字面量的 TableName 方法
+func tableNameMethod(fn *ast.FuncDecl) (string, string, bool) {
+	if fn.Name.Name != "TableName" || fn.Recv == nil || len(fn.Recv.List) != 1 || fn.Body == nil || len(fn.Body.List) != 1 {
+		return "", "", false
+	}
+	recv := fn.Recv.List[0].Type
+	if star, ok := recv.(*ast.StarExpr); ok {
+		recv = star.X
+	}
+	ident, ok := recv.(*ast.Ident)
+	if !ok {
+		return "", "", false
+	}
+	ret, ok := fn.Body.List[0].(*ast.ReturnStmt)
+	if !ok || len(ret.Results) != 1 {
+		return "", "", false
+	}
+	lit, ok := ret.Results[0].(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return "", "", false
+	}
+	table, err := strconv.Unquote(lit.Value)
+	if err != nil {
+		return "", "", false
+	}
+	return ident.Name, table, true
+}
+
+// gormModelColumns 嵌入 gorm.Model 时包含的列
+var gormModelColumns = []ModelField{
+	{Name: "ID", Column: "id", GoType: "uint"},
+	{Name: "CreatedAt", Column: "created_at", GoType: "time.Time"},
+	{Name: "UpdatedAt", Column: "updated_at", GoType: "time.Time"},
+	{Name: "DeletedAt", Column: "deleted_at", GoType: "gorm.DeletedAt"},
+}
+
+// parseModel 解析结构体字段及其 gorm 标签。匿名嵌入或带 embedded 标签的结构体从 structs（同一目录中的结构体）展开，
+// gorm.Model 按其固定的列展开，无法解析的嵌入返回错误，避免其中的列被误报为数据库新增
+func parseModel(st *ast.StructType, table string, structs map[string]*ast.StructType) (Model, error) {
+	m := Model{Table: table}
+	indexes := make(map[string]*Index)
+	priorities := make(map[string]map[string]int)
+	var indexOrder []string
+
+	var addFields func(st *ast.StructType, prefix string, parents []string) error
+	addFields = func(st *ast.StructType, prefix string, parents []string) error {
+		for _, field := range st.Fields.List {
+			goType := types.ExprString(field.Type)
+			var tag reflect.StructTag
+			if field.Tag != nil {
+				if s, err := strconv.Unquote(field.Tag.Value); err == nil {
+					tag = reflect.StructTag(s)
+				}
+			}
+			gorm := parseGormTag(tag.Get("gorm"))
+			if _, ok := gorm["-"]; ok {
+				continue
+			}
+
+			if _, embedded := gorm["embedded"]; len(field.Names) == 0 || embedded {
+				if len(field.Names) > 0 && !field.Names[0].IsExported() {
+					continue
+				}
+				embedPrefix := prefix + gorm["embeddedprefix"]
+				if goType == "gorm.Model" || goType == "*gorm.Model" {
+					for _, f := range gormModelColumns {
+						f.Column = embedPrefix + f.Column
+						m.Fields = append(m.Fields, f)
+					}
+					continue
+				}
+				name := strings.TrimPrefix(goType, "*")
+				inner, ok := structs[name]
+				if !ok {
+					return fmt.Errorf("cannot resolve embedded struct %s, define it in the model directory", goType)
+				}
+				for _, p := range parents {
+					if p == name {
+						return fmt.Errorf("embedded struct %s embeds itself", name)
+					}
+				}
+				if err := addFields(inner, embedPrefix, append(parents, name)); err != nil {
+					return err
+				}
+				continue
+			}
+
+			for _, name := range field.Names {
+				if !name.IsExported() {
+					continue
+				}
+				f := ModelField{Name: name.Name, Column: gorm["column"], GoType: goType, DBType: gorm["type"]}
+				if f.Column == "" {
+					f.Column = prefix + toSnakeCase(name.Name)
+				}
+				m.Fields = append(m.Fields, f)
+
+				for _, kind := range []string{"index", "uniqueIndex"} {
+					value, ok := gorm[kind]
+					if !ok {
+						continue
+					}
+					idxName, priority := parseIndexTag(value, table, f.Column)
+					idx, ok := indexes[idxName]
+					if !ok {
+						idx = &Index{Name: idxName}
+						indexes[idxName] = idx
+						priorities[idxName] = make(map[string]int)
+						indexOrder = append(indexOrder, idxName)
+					}
+					idx.Unique = idx.Unique || kind == "uniqueIndex"
+					idx.Columns = append(idx.Columns, f.Column)
+					priorities[idxName][f.Column] = priority
+				}
+			}
+		}
+		return nil
+	}
+	if err := addFields(st, "", nil); err != nil {
+		return Model{}, err
+	}
+
+	for _, name := range indexOrder {
+		idx := indexes[name]
+		p := priorities[name]
+		sort.SliceStable(idx.Columns, func(i, j int) bool { return p[idx.Columns[i]] < p[idx.Columns[j]] })
+		m.Indexes = append(m.Indexes, *idx)
+	}
+	return m, nil
+}
+
+// parseIndexTag 解析 index / uniqueIndex 标签值，如 uk_name,priority:2；未命名时使用 gorm 的默认命名
+func parseIndexTag(value, table, column string) (string, int) {
+	name, priority := "", 10
+	for i, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if i == 0 && !strings.Contains(part, ":") {
+			name = part
+			continue
+		}
+		if k, v, ok := strings.Cut(part, ":"); ok && strings.EqualFold(k, "priority") {
+			if n, err := strconv.Atoi(v); err == nil {
+				priority = n
+			}
+		}
+	}
+	if name == "" {
+		name = "idx_" + table + "_" + column
+	}
+	return name, priority
+}
+
+// parseGormTag 将 gorm 标签拆分为键值，支持 \; 转义；键统一为生成代码使用的大小写
+func parseGormTag(tag string) map[string]string {
+	settings := make(map[string]string)
+	var parts []string
+	var cur strings.Builder
+	for i := 0; i < len(tag); i++ {
+		switch {
+		case tag[i] == '\\' && i+1 < len(tag) && tag[i+1] == ';':
+			cur.WriteString(`\;`)
+			i++
+		case tag[i] == ';':
+			parts = append(parts, cur.String())
+			cur.Reset()
+		default:
+			cur.WriteByte(tag[i])
+		}
+	}
+	parts = append(parts, cur.String())
+
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		k, v, _ := strings.Cut(part, ":")
+		switch strings.ToLower(k) {
+		case "uniqueindex":
+			k = "uniqueIndex"
+		default:
+			k = strings.ToLower(k)
+		}
+		settings[k] = v
+	}
+	return settings
+}
+
+// toSnakeCase 按 gorm 默认命名规则将字段名转为列名，如 UserID 转为 user_id
+func toSnakeCase(s string) string {
+	runes := []rune(s)
+	var sb strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 && (unicode.IsLower(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+				sb.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// Diff 比较模型与数据库中的表结构。模型对应的表不存在时报告 removed，
+// 按 Include / Exclude 过滤后没有模型的表报告 added。
+// 列的 Go 类型按当前的 Types（可空列映射、类型覆盖等）推导，需与生成模型时的配置一致，否则会报告类型变化
+func (g *Generator) Diff(models []Model) (*SchemaDiff, error) {
+	in, err := g.getIntrospector()
+	if err != nil {
+		return nil, err
+	}
+	tables, err := g.Tables()
+	if err != nil {
+		return nil, err
+	}
+	exists := make(map[string]bool, len(tables))
+	for _, table := range tables {
+		exists[table] = true
+	}
+
+	diff := &SchemaDiff{Tables: []TableDiff{}}
+	modeled := make(map[string]bool, len(models))
+	for _, m := range models {
+		modeled[m.Table] = true
+		if len(g.config.Include) > 0 && !matchAny(g.config.Include, m.Table) || matchAny(g.config.Exclude, m.Table) {
+			continue
+		}
+
+		td := TableDiff{Table: m.Table, Struct: m.Struct, File: m.File}
+		if !exists[m.Table] {
+			td.Change = ChangeRemoved
+			diff.Tables = append(diff.Tables, td)
+			continue
+		}
+
+		columns, err := g.getColumns(m.Table)
+		if err != nil {
+			return nil, fmt.Errorf("表 %s: %w", m.Table, err)
+		}
+		indexes, err := in.Indexes(m.Table)
+		if err != nil {
+			return nil, fmt.Errorf("表 %s: %w", m.Table, err)
+		}
+		td.Columns = diffColumns(m.Fields, columns)
+		td.Indexes = diffIndexes(m.Indexes, indexes)
+		if len(td.Columns) > 0 || len(td.Indexes) > 0 {
+			diff.Tables = append(diff.Tables, td)
+		}
+	}
+
+	for _, table := range tables {
+		if !modeled[table] {
+			diff.Tables = append(diff.Tables, TableDiff{Table: table, Change: ChangeAdded})
+		}
+	}
+	return diff, nil
+}
+
+// diffColumns 按列名比较字段与数据库列；模型标签中没有 type 时只比较 Go 类型
+func diffColumns(fields []ModelField, columns []Column) []ColumnDiff {
+	var diffs []ColumnDiff
+	byName := make(map[string]ModelField, len(fields))
+	for _, f := range fields {
+		byName[f.Column] = f
+	}
+
+	seen := make(map[string]bool, len(columns))
+	for _, col := range columns {
+		seen[col.Field] = true
+		dbType := escapeTagValue(col.Type)
+		f, ok := byName[col.Field]
+		if !ok {
+			diffs = append(diffs, ColumnDiff{Column: col.Field, Change: ChangeAdded, DBType: dbType, DBGoType: col.GoType})
+			continue
+		}
+
+		typeChanged := f.DBType != "" && !strings.EqualFold(f.DBType, dbType)
+		if typeChanged || f.GoType != col.GoType {
+			d := ColumnDiff{Column: col.Field, Change: ChangeChanged, ModelGoType: f.GoType, DBGoType: col.GoType}
+			if f.DBType != "" {
+				d.ModelType, d.DBType = f.DBType, dbType
+			}
+			diffs = append(diffs, d)
+		}
+	}
+
+	for _, f := range fields {
+		if !seen[f.Column] {
+			diffs = append(diffs, ColumnDiff{Column: f.Column, Change: ChangeRemoved, ModelType: f.DBType, ModelGoType: f.GoType})
+		}
+	}
+	return diffs
+}
+
+// diffIndexes 按索引名比较，主键不参与比较
+func diffIndexes(model, db []Index) []IndexDiff {
+	var diffs []IndexDiff
+	byName := make(map[string]Index, len(model))
+	for _, idx := range model {
+		byName[idx.Name] = idx
+	}
+
+	seen := make(map[string]bool, len(db))
+	for _, idx := range db {
+		if idx.Primary {
+			continue
+		}
+		seen[idx.Name] = true
+		m, ok := byName[idx.Name]
+		if !ok {
+			diffs = append(diffs, IndexDiff{Index: idx.Name, Change: ChangeAdded, DBColumns: idx.Columns, DBUnique: idx.Unique})
+			continue
+		}
+		if m.Unique != idx.Unique || strings.Join(m.Columns, ",") != strings.Join(idx.Columns, ",") {
+			diffs = append(diffs, IndexDiff{
+				Index:        idx.Name,
+				Change:       ChangeChanged,
+				ModelColumns: m.Columns,
+				DBColumns:    idx.Columns,
+				ModelUnique:  m.Unique,
+				DBUnique:     idx.Unique,
+			})
+		}
+	}
+
+	for _, idx := range model {
+		if !seen[idx.Name] {
+			diffs = append(diffs, IndexDiff{Index: idx.Name, Change: ChangeRemoved, ModelColumns: idx.Columns, ModelUnique: idx.Unique})
+		}
+	}
+	return diffs
+}
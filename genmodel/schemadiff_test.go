@@ -0,0 +1,176 @@
+package genmodel
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	dir := t.TempDir()
+	src := `package model
+
+type Users struct {
+	ID       uint64  ` + "`" + `gorm:"column:id;type:bigint unsigned;primaryKey;autoIncrement"` + "`" + `
+	Email    string  ` + "`" + `gorm:"column:email;type:varchar(64);not null;uniqueIndex:uk_email"` + "`" + `
+	Nickname string  ` + "`" + `gorm:"column:nickname;type:varchar(32);index:idx_name,priority:2"` + "`" + `
+	TenantID uint64  ` + "`" + `gorm:"index:idx_name,priority:1"` + "`" + `
+	Age      int     ` + "`" + `gorm:"column:age"` + "`" + `
+	Ignored  string  ` + "`" + `gorm:"-"` + "`" + `
+}
+
+func (Users) TableName() string { return "users" }
+
+type Orders struct {
+	ID uint64
+}
+
+func (*Orders) TableName() string { return "orders" }
+
+type helper struct{}
+`
+	if err := os.WriteFile(filepath.Join(dir, "users.go"), []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	models, err := LoadModels(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(models) != 2 || models[0].Table != "users" || models[1].Table != "orders" {
+		t.Fatalf("unexpected models: %+v", models)
+	}
+	if want := []Index{{Name: "uk_email", Columns: []string{"email"}, Unique: true}, {Name: "idx_name", Columns: []string{"tenant_id", "nickname"}}}; !reflect.DeepEqual(models[0].Indexes, want) {
+		t.Errorf("indexes = %+v, want %+v", models[0].Indexes, want)
+	}
+
+	in := &fakeIntrospector{
+		columns: []Column{
+			{Field: "id", Type: "bigint unsigned", IsPrimary: true, AutoIncrement: true},
+			{Field: "email", Type: "varchar(128)"},
+			{Field: "nickname", Type: "varchar(32)", Nullable: true},
+			{Field: "tenant_id", Type: "bigint unsigned"},
+			{Field: "created_at", Type: "datetime"},
+		},
+		indexes: []Index{
+			{Name: "PRIMARY", Columns: []string{"id"}, Unique: true, Primary: true},
+			{Name: "uk_email", Columns: []string{"email"}},
+			{Name: "idx_name", Columns: []string{"tenant_id", "nickname"}},
+			{Name: "idx_created_at", Columns: []string{"created_at"}},
+		},
+	}
+	g := NewGenerator(&Config{Introspector: in})
+	diff, err := g.Diff(models)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !diff.HasChanges() || len(diff.Tables) != 2 {
+		t.Fatalf("unexpected diff: %+v", diff.Tables)
+	}
+
+	users := diff.Tables[0]
+	wantColumns := []ColumnDiff{
+		{Column: "email", Change: ChangeChanged, ModelType: "varchar(64)", DBType: "varchar(128)", ModelGoType: "string", DBGoType: "string"},
+		{Column: "nickname", Change: ChangeChanged, ModelType: "varchar(32)", DBType: "varchar(32)", ModelGoType: "string", DBGoType: "*string"},
+		{Column: "created_at", Change: ChangeAdded, DBType: "datetime", DBGoType: "time.Time"},
+		{Column: "age", Change: ChangeRemoved, ModelGoType: "int"},
+	}
+	if !reflect.DeepEqual(users.Columns, wantColumns) {
+		t.Errorf("columns =\n%+v\nwant\n%+v", users.Columns, wantColumns)
+	}
+	wantIndexes := []IndexDiff{
+		{Index: "uk_email", Change: ChangeChanged, ModelColumns: []string{"email"}, DBColumns: []string{"email"}, ModelUnique: true},
+		{Index: "idx_created_at", Change: ChangeAdded, DBColumns: []string{"created_at"}},
+	}
+	if !reflect.DeepEqual(users.Indexes, wantIndexes) {
+		t.Errorf("indexes =\n%+v\nwant\n%+v", users.Indexes, wantIndexes)
+	}
+	if orders := diff.Tables[1]; orders.Table != "orders" || orders.Change != ChangeRemoved {
+		t.Errorf("orders = %+v, want removed", orders)
+	}
+
+	var buf bytes.Buffer
+	if err := diff.WriteText(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte("~ column email: type varchar(64) -> varchar(128)")) {
+		t.Errorf("unexpected text report:\n%s", buf.String())
+	}
+}
+
+func TestLoadModelsEmbedded(t *testing.T) {
+	dir := t.TempDir()
+	src := `package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type BaseModel struct {
+	ID        uint64 ` + "`" + `gorm:"primaryKey"` + "`" + `
+	CreatedAt time.Time
+	TenantID  uint64 ` + "`" + `gorm:"index"` + "`" + `
+}
+
+type Audit struct {
+	By string
+}
+
+type Posts struct {
+	*BaseModel
+	Title   string
+	Created Audit ` + "`" + `gorm:"embedded;embeddedPrefix:created_"` + "`" + `
+}
+
+func (Posts) TableName() string { return "posts" }
+
+type Legacy struct {
+	gorm.Model
+	Name string
+}
+
+func (Legacy) TableName() string { return "legacy" }
+`
+	if err := os.WriteFile(filepath.Join(dir, "posts.go"), []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	models, err := LoadModels(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var columns []string
+	for _, f := range models[0].Fields {
+		columns = append(columns, f.Column)
+	}
+	if want := []string{"id", "created_at", "tenant_id", "title", "created_by"}; !reflect.DeepEqual(columns, want) {
+		t.Errorf("posts columns = %v, want %v", columns, want)
+	}
+	if want := []Index{{Name: "idx_posts_tenant_id", Columns: []string{"tenant_id"}}}; !reflect.DeepEqual(models[0].Indexes, want) {
+		t.Errorf("posts indexes = %+v, want %+v", models[0].Indexes, want)
+	}
+	if n := len(models[1].Fields); n != len(gormModelColumns)+1 {
+		t.Errorf("legacy has %d fields, want gorm.Model columns and name", n)
+	}
+
+	// 无法解析的嵌入返回错误，而不是把其中的列报告为数据库新增
+	unresolved := `package model
+
+type Orders struct {
+	common.Base
+	Amount int
+}
+
+func (Orders) TableName() string { return "orders" }
+`
+	if err := os.WriteFile(filepath.Join(dir, "posts.go"), []byte(unresolved), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadModels(dir); err == nil {
+		t.Fatal("expected an error for an unresolved embedded struct")
+	}
+}
@@ -0,0 +1,174 @@
+package genmodel
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// json 标签的命名风格
+const (
+	JSONCaseSnake = "snake" // user_name（默认）
+	JSONCaseCamel = "camel" // userName
+	JSONCaseNone  = "none"  // 不生成 json 标签
+)
+
+// Index 索引信息，Columns 按索引内的顺序排列
+type Index struct {
+	Name    string
+	Columns []string
+	Unique  bool
+	Primary bool
+}
+
+// pgCast PostgreSQL 默认值中的类型转换后缀，如 'abc'::character varying
+var pgCast = regexp.MustCompile(`::[a-z ]+(\[\])?$`)
+
+// buildTags 生成列的 gorm、json 与 binding 标签
+func (g *Generator) buildTags(col *Column, indexes []Index) {
+	col.GormTag = gormTag(col, indexes)
+	col.JSONTag = g.jsonTag(col)
+	col.BindingTag = bindingTag(col)
+
+	tags := []string{fmt.Sprintf("gorm:%s", strconv.Quote(col.GormTag))}
+	if col.JSONTag != "" {
+		tags = append(tags, fmt.Sprintf("json:%s", strconv.Quote(col.JSONTag)))
+	}
+	if g.config.BindingTags && col.BindingTag != "" {
+		tags = append(tags, fmt.Sprintf("binding:%s", strconv.Quote(col.BindingTag)))
+	}
+	col.Tag = strings.Join(tags, " ")
+}
+
+// gormTag 生成完整的 gorm 标签
+func gormTag(col *Column, indexes []Index) string {
+	parts := []string{"column:" + col.Field}
+	if col.Type != "" {
+		parts = append(parts, "type:"+escapeTagValue(col.Type))
+	}
+	if col.Size > 0 {
+		parts = append(parts, fmt.Sprintf("size:%d", col.Size))
+	}
+	if col.IsPrimary {
+		parts = append(parts, "primaryKey")
+	}
+	if col.AutoIncrement {
+		parts = append(parts, "autoIncrement")
+	}
+	if !col.Nullable && !col.IsPrimary {
+		parts = append(parts, "not null")
+	}
+	if def, ok := defaultValue(col); ok {
+		parts = append(parts, "default:"+def)
+	}
+
+	for _, idx := range indexes {
+		if idx.Primary {
+			continue
+		}
+		for i, name := range idx.Columns {
+			if name != col.Field {
+				continue
+			}
+			kind := "index"
+			if idx.Unique {
+				kind = "uniqueIndex"
+			}
+			tag := kind + ":" + escapeTagValue(idx.Name)
+			if len(idx.Columns) > 1 {
+				tag += fmt.Sprintf(",priority:%d", i+1)
+			}
+			parts = append(parts, tag)
+		}
+	}
+
+	if col.Comment != nil && *col.Comment != "" {
+		parts = append(parts, "comment:"+escapeTagValue(*col.Comment))
+	}
+	return strings.Join(parts, ";")
+}
+
+// defaultValue 返回可写入标签的默认值，自增序列等由数据库生成的默认值不写入
+func defaultValue(col *Column) (string, bool) {
+	if col.Default == nil || col.AutoIncrement {
+		return "", false
+	}
+
+	def := pgCast.ReplaceAllString(*col.Default, "")
+	if strings.EqualFold(def, "NULL") {
+		return "", false
+	}
+	if def == "" {
+		return "''", true
+	}
+	return escapeTagValue(def), true
+}
+
+// escapeTagValue 转义 gorm 标签值中的分隔符，并去掉无法出现在反引号结构体标签中的字符
+func escapeTagValue(s string) string {
+	s = strings.NewReplacer("`", "", "\n", " ", "\r", "").Replace(s)
+	return strings.ReplaceAll(s, ";", `\;`)
+}
+
+// jsonTag 按配置的命名风格生成 json 标签
+func (g *Generator) jsonTag(col *Column) string {
+	switch g.config.JSONCase {
+	case JSONCaseNone:
+		return ""
+	case JSONCaseCamel:
+		return lowerFirst(g.toCamelCase(col.Field))
+	default:
+		return snakeCase(col.Field)
+	}
+}
+
+// snakeCase 将列名转为 snake_case，如 createdAt、CreatedAt 转为 created_at，已有的下划线保留
+func snakeCase(s string) string {
+	parts := strings.Split(s, "_")
+	for i, p := range parts {
+		parts[i] = toSnakeCase(p)
+	}
+	return strings.Join(parts, "_")
+}
+
+// bindingTag 根据列约束生成 validator 标签：无默认值的非空字符串列为 required，带长度的字符串列限制 max
+func bindingTag(col *Column) string {
+	goType := strings.TrimPrefix(col.GoType, "*")
+	if goType != "string" {
+		return ""
+	}
+
+	var rules []string
+	if !col.Nullable && col.Default == nil && !col.AutoIncrement {
+		rules = append(rules, "required")
+	} else if col.Size > 0 {
+		rules = append(rules, "omitempty")
+	}
+	if col.Size > 0 {
+		rules = append(rules, fmt.Sprintf("max=%d", col.Size))
+	}
+	return strings.Join(rules, ",")
+}
+
+// columnSize 返回字符类型的长度，如 varchar(255) 返回 255
+func columnSize(typ string) int {
+	base, args, _ := parseSQLType(typ)
+	if !strings.Contains(base, "char") {
+		return 0
+	}
+	size, err := strconv.Atoi(strings.TrimSpace(args))
+	if err != nil {
+		return 0
+	}
+	return size
+}
+
+// lowerFirst 将首字母转为小写
+func lowerFirst(s string) string {
+	for i, r := range s {
+		return string(unicode.ToLower(r)) + s[i+len(string(r)):]
+	}
+	return s
+}
@@ -0,0 +1,52 @@
+package genmodel
+
+import "testing"
+
+func TestBuildTags(t *testing.T) {
+	def := "active"
+	comment := "状态;备注"
+	indexes := []Index{
+		{Name: "PRIMARY", Columns: []string{"id"}, Unique: true, Primary: true},
+		{Name: "uk_tenant_status", Columns: []string{"tenant_id", "status"}, Unique: true},
+		{Name: "idx_status", Columns: []string{"status"}},
+	}
+
+	g := NewGenerator(&Config{JSONCase: JSONCaseCamel, BindingTags: true})
+	col := Column{
+		Field:   "status",
+		Type:    "varchar(16)",
+		GoType:  "string",
+		Default: &def,
+		Comment: &comment,
+		Size:    16,
+	}
+	g.buildTags(&col, indexes)
+
+	const want = `gorm:"column:status;type:varchar(16);size:16;not null;default:active;uniqueIndex:uk_tenant_status,priority:2;index:idx_status;comment:状态\\;备注" json:"status" binding:"omitempty,max=16"`
+	if col.Tag != want {
+		t.Errorf("tag =\n%s\nwant\n%s", col.Tag, want)
+	}
+
+	id := Column{Field: "user_id", Type: "bigint", GoType: "int64", IsPrimary: true, AutoIncrement: true}
+	g.buildTags(&id, indexes)
+	if want := `gorm:"column:user_id;type:bigint;primaryKey;autoIncrement" json:"userId"`; id.Tag != want {
+		t.Errorf("tag = %s, want %s", id.Tag, want)
+	}
+}
+
+func TestJSONTagSnakeCase(t *testing.T) {
+	g := NewGenerator(&Config{JSONCase: JSONCaseSnake})
+	for field, want := range map[string]string{
+		"createdAt":    "created_at",
+		"CreatedAt":    "created_at",
+		"user_id":      "user_id",
+		"UserID":       "user_id",
+		"HTTPStatus":   "http_status",
+		"orderItem_ID": "order_item_id",
+		"NAME":         "name",
+	} {
+		if got := g.jsonTag(&Column{Field: field}); got != want {
+			t.Errorf("jsonTag(%q) = %q, want %q", field, got, want)
+		}
+	}
+}
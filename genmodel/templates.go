@@ -0,0 +1,311 @@
+package genmodel
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// 内置模板名称
+const (
+	TemplateModel      = "model"      // GORM 模型
+	TemplateRepository = "repository" // 基于 database.Repository 的数据访问
+	TemplateDTO        = "dto"        // 创建/更新请求与响应 DTO
+	TemplateConverter  = "converter"  // 模型与 DTO 之间的转换函数
+)
+
+// Template 命名模板：每个模板为每张表生成一个文件，写入各自的输出目录
+type Template struct {
+	Name       string // 模板名称，内置模板为 model、repository、dto、converter
+	Text       string // 模板内容，为空时使用同名的内置模板
+	OutputPath string // 输出目录，为空时使用 Config.OutputPath
+	Package    string // 包名，为空时使用 Config.PackageName
+	ImportPath string // 输出目录的导入路径，其他目录的模板引用本模板生成的类型时需要
+}
+
+// TemplateData 传给模板的数据
+type TemplateData struct {
+	Name            string   // 当前模板名称
+	PackageName     string   // 当前模板的包名
+	StructName      string   // 模型结构体名
+	VarName         string   // 首字母小写的结构体名
+	TableName       string   // 表名
+	Imports         []string // 列类型需要导入的包
+	RefImports      []string // 引用其他模板所需的导入，如 model "github.com/acme/app/internal/model"
+	Columns         []Column // 全部列
+	Fields          []string // 全部列名
+	PrimaryKey      *Column  // 第一个主键列
+	WritableColumns []Column // 可由请求写入的列：排除自增列与 created_at、updated_at、deleted_at
+	ResponseColumns []Column // 响应中输出的列：排除 deleted_at
+	BindingTags     bool     // 是否生成 binding 校验标签，对应 Config.BindingTags
+}
+
+// builtinTemplates 内置模板
+var builtinTemplates = map[string]string{
+	TemplateModel:      defaultTemplate,
+	TemplateRepository: repositoryTemplate,
+	TemplateDTO:        dtoTemplate,
+	TemplateConverter:  converterTemplate,
+}
+
+// 由 GORM 自动维护的时间列
+var autoTimeColumns = map[string]bool{
+	"created_at": true,
+	"updated_at": true,
+	"deleted_at": true,
+}
+
+// templates 返回生效的模板列表，未配置 Templates 时只生成模型
+func (g *Generator) templates() ([]Template, error) {
+	specs := g.config.Templates
+	if len(specs) == 0 {
+		specs = []Template{{Name: TemplateModel, Text: g.config.Template}}
+	}
+
+	resolved := make([]Template, len(specs))
+	for i, spec := range specs {
+		if spec.Text == "" {
+			text, ok := builtinTemplates[spec.Name]
+			if !ok {
+				return nil, fmt.Errorf("genmodel: template %q has no text", spec.Name)
+			}
+			spec.Text = text
+		}
+		if spec.OutputPath == "" {
+			spec.OutputPath = g.config.OutputPath
+		}
+		if spec.Package == "" {
+			spec.Package = g.config.PackageName
+		}
+		resolved[i] = spec
+	}
+	return resolved, nil
+}
+
+// renderTemplate 使用一个模板生成文件
+func (g *Generator) renderTemplate(spec Template, all []Template, data TemplateData) (*File, error) {
+	sameDir := func(other Template) bool {
+		return filepath.Clean(other.OutputPath) == filepath.Clean(spec.OutputPath)
+	}
+
+	data.Name = spec.Name
+	data.PackageName = spec.Package
+	data.RefImports = nil
+	for _, other := range all {
+		if other.ImportPath == "" || sameDir(other) {
+			continue
+		}
+		if path.Base(other.ImportPath) == other.Package {
+			data.RefImports = append(data.RefImports, strconv.Quote(other.ImportPath))
+		} else {
+			data.RefImports = append(data.RefImports, fmt.Sprintf("%s %q", other.Package, other.ImportPath))
+		}
+	}
+
+	funcs := template.FuncMap{
+		// qualify 返回引用指定模板生成的类型时需要的包名前缀；被引用的模板在其他目录却没有 ImportPath 时报错
+		"qualify": func(name string) (string, error) {
+			for _, other := range all {
+				if other.Name == name && !sameDir(other) {
+					if other.ImportPath == "" {
+						return "", fmt.Errorf("template %s references %s in %s, set its ImportPath", spec.Name, name, other.OutputPath)
+					}
+					return other.Package + ".", nil
+				}
+			}
+			return "", nil
+		},
+		// jsonName 返回列在 DTO 中的 json 名称
+		"jsonName": func(col Column) string {
+			if col.JSONTag != "" {
+				return col.JSONTag
+			}
+			return strings.ToLower(col.Field)
+		},
+	}
+
+	tmpl, err := template.New(spec.Name).Funcs(funcs).Parse(spec.Text)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf strings.Builder
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	content, err := finalize([]byte(buf.String()))
+	if err != nil {
+		return nil, fmt.Errorf("表 %s 模板 %s: %w", data.TableName, spec.Name, err)
+	}
+
+	name := strings.ToLower(data.TableName)
+	if spec.Name != TemplateModel {
+		name += "_" + spec.Name
+	}
+	return &File{
+		Table:   data.TableName,
+		Path:    filepath.Join(spec.OutputPath, name+".go"),
+		Content: content,
+	}, nil
+}
+
+// templateData 构造表的模板数据
+func (g *Generator) templateData(tableName string, columns []Column) TemplateData {
+	structName := g.toCamelCase(tableName)
+	data := TemplateData{
+		StructName:  structName,
+		VarName:     lowerFirst(structName),
+		TableName:   tableName,
+		Imports:     collectImports(columns),
+		Columns:     columns,
+		Fields:      g.getFieldList(columns),
+		BindingTags: g.config.BindingTags,
+	}
+
+	for i := range columns {
+		col := columns[i]
+		if col.IsPrimary && data.PrimaryKey == nil {
+			data.PrimaryKey = &columns[i]
+		}
+		if col.Field != "deleted_at" {
+			data.ResponseColumns = append(data.ResponseColumns, col)
+		}
+		if !col.AutoIncrement && !autoTimeColumns[col.Field] {
+			data.WritableColumns = append(data.WritableColumns, col)
+		}
+	}
+	return data
+}
+
+const defaultTemplate = `package {{.PackageName}}
+{{if .Imports}}
+import (
+{{- range .Imports}}
+	"{{.}}"
+{{- end}}
+)
+{{end}}
+type {{.StructName}} struct {
+{{- range .Columns}}
+	{{.GoField}} {{.GoType}} ` + "`{{.Tag}}`" + `
+{{- end}}
+}
+
+func ({{.StructName}}) TableName() string {
+	return "{{.TableName}}"
+}
+
+func ({{.StructName}}) GetFields() []string {
+	return []string{
+{{- range .Fields}}
+		"{{.}}",
+{{- end}}
+	}
+}
+`
+
+const repositoryTemplate = `package {{.PackageName}}
+
+import (
+	"context"
+
+	"github.com/shrimps80/go-service-utils/database"
+	"gorm.io/gorm"
+{{- range .RefImports}}
+	{{.}}
+{{- end}}
+)
+
+// {{.StructName}}Repository {{.TableName}} 表的数据访问
+type {{.StructName}}Repository struct {
+	*database.Repository[{{qualify "model"}}{{.StructName}}]
+}
+
+// New{{.StructName}}Repository 创建 {{.TableName}} 表的数据访问
+func New{{.StructName}}Repository(db *gorm.DB, opts *database.RepositoryOptions) *{{.StructName}}Repository {
+	return &{{.StructName}}Repository{
+		Repository: database.NewRepository[{{qualify "model"}}{{.StructName}}](db, opts),
+	}
+}
+
+// Update 保存全部字段
+func (r *{{.StructName}}Repository) Update(ctx context.Context, entity *{{qualify "model"}}{{.StructName}}) error {
+	return r.DB(ctx).Save(entity).Error
+}
+`
+
+const dtoTemplate = `package {{.PackageName}}
+
+import (
+{{- range .Imports}}
+	"{{.}}"
+{{- end}}
+)
+
+// Create{{.StructName}}Request 创建 {{.TableName}} 的请求
+type Create{{.StructName}}Request struct {
+{{- range .WritableColumns}}
+	{{.GoField}} {{.GoType}} ` + "`" + `json:"{{jsonName .}}"{{if and $.BindingTags .BindingTag}} binding:"{{.BindingTag}}"{{end}}` + "`" + `
+{{- end}}
+}
+
+// Update{{.StructName}}Request 更新 {{.TableName}} 的请求
+type Update{{.StructName}}Request struct {
+{{- range .WritableColumns}}
+	{{.GoField}} {{.GoType}} ` + "`" + `json:"{{jsonName .}}"{{if and $.BindingTags .BindingTag}} binding:"{{.BindingTag}}"{{end}}` + "`" + `
+{{- end}}
+}
+
+// {{.StructName}}Response {{.TableName}} 的响应
+type {{.StructName}}Response struct {
+{{- range .ResponseColumns}}
+	{{.GoField}} {{.GoType}} ` + "`" + `json:"{{jsonName .}}"` + "`" + `
+{{- end}}
+}
+`
+
+const converterTemplate = `package {{.PackageName}}
+
+import (
+	"github.com/shrimps80/go-service-utils/utils"
+{{- range .RefImports}}
+	{{.}}
+{{- end}}
+)
+
+// To{{.StructName}}Response 将模型转换为响应
+func To{{.StructName}}Response(m *{{qualify "model"}}{{.StructName}}) *{{qualify "dto"}}{{.StructName}}Response {
+	if m == nil {
+		return nil
+	}
+	return &{{qualify "dto"}}{{.StructName}}Response{
+{{- range .ResponseColumns}}
+		{{.GoField}}: m.{{.GoField}},
+{{- end}}
+	}
+}
+
+// To{{.StructName}}Responses 批量将模型转换为响应
+func To{{.StructName}}Responses(list []*{{qualify "model"}}{{.StructName}}) []*{{qualify "dto"}}{{.StructName}}Response {
+	return utils.MapPtr(list, To{{.StructName}}Response)
+}
+
+// {{.StructName}}FromCreateRequest 将创建请求转换为模型
+func {{.StructName}}FromCreateRequest(req *{{qualify "dto"}}Create{{.StructName}}Request) *{{qualify "model"}}{{.StructName}} {
+	return &{{qualify "model"}}{{.StructName}}{
+{{- range .WritableColumns}}
+		{{.GoField}}: req.{{.GoField}},
+{{- end}}
+	}
+}
+
+// Apply{{.StructName}}UpdateRequest 将更新请求应用到模型
+func Apply{{.StructName}}UpdateRequest(m *{{qualify "model"}}{{.StructName}}, req *{{qualify "dto"}}Update{{.StructName}}Request) {
+{{- range .WritableColumns}}
+	m.{{.GoField}} = req.{{.GoField}}
+{{- end}}
+}
+`
@@ -0,0 +1,172 @@
+package genmodel
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakeIntrospector 固定表结构，用于不依赖数据库的生成测试
+type fakeIntrospector struct {
+	columns []Column
+	indexes []Index
+}
+
+func (f *fakeIntrospector) Tables() ([]string, error) { return []string{"users"}, nil }
+func (f *fakeIntrospector) Columns(string) ([]Column, error) {
+	return append([]Column(nil), f.columns...), nil
+}
+func (f *fakeIntrospector) Indexes(string) ([]Index, error) { return f.indexes, nil }
+func (f *fakeIntrospector) GoType(col Column) string        { return (&mysqlIntrospector{}).GoType(col) }
+
+func TestRenderTemplates(t *testing.T) {
+	in := &fakeIntrospector{columns: []Column{
+		{Field: "id", Type: "bigint unsigned", IsPrimary: true, AutoIncrement: true},
+		{Field: "email", Type: "varchar(64)"},
+		{Field: "created_at", Type: "datetime"},
+		{Field: "deleted_at", Type: "datetime", Nullable: true},
+	}}
+
+	g := NewGenerator(&Config{
+		Introspector: in,
+		OutputPath:   "internal/model",
+		PackageName:  "model",
+		BindingTags:  true,
+		Templates: []Template{
+			{Name: TemplateModel, ImportPath: "example.com/app/internal/model"},
+			{Name: TemplateRepository, OutputPath: "internal/repository", Package: "repository"},
+			{Name: TemplateDTO, OutputPath: "internal/dto", Package: "dto", ImportPath: "example.com/app/internal/dto"},
+			{Name: TemplateConverter, OutputPath: "internal/dto", Package: "dto"},
+		},
+	})
+
+	files, err := g.Render("users")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string][]string{
+		"internal/model/users.go":                 {"type Users struct", `"time"`},
+		"internal/repository/users_repository.go": {`"example.com/app/internal/model"`, "database.Repository[model.Users]"},
+		"internal/dto/users_dto.go":               {"type CreateUsersRequest struct", `binding:"required,max=64"`, "CreatedAt time.Time"},
+		"internal/dto/users_converter.go":         {"m *model.Users) *UsersResponse", "utils.MapPtr"},
+	}
+	if len(files) != len(want) {
+		t.Fatalf("got %d files, want %d", len(files), len(want))
+	}
+	for _, f := range files {
+		checks, ok := want[filepath.ToSlash(f.Path)]
+		if !ok {
+			t.Errorf("unexpected file %s", f.Path)
+			continue
+		}
+		for _, s := range checks {
+			if !bytes.Contains(f.Content, []byte(s)) {
+				t.Errorf("%s does not contain %q:\n%s", f.Path, s, f.Content)
+			}
+		}
+	}
+
+	// 请求 DTO 不包含自增主键与自动维护的时间列，响应不包含 deleted_at
+	dto := files[2].Content
+	if bytes.Contains(dto, []byte("DeletedAt")) || bytes.Count(dto, []byte("CreatedAt")) != 1 {
+		t.Errorf("unexpected dto columns:\n%s", dto)
+	}
+}
+
+// testColumns 覆盖自增主键、字符串、时间与可空列
+func testColumns() []Column {
+	return []Column{
+		{Field: "id", Type: "bigint unsigned", IsPrimary: true, AutoIncrement: true},
+		{Field: "email", Type: "varchar(64)"},
+		{Field: "created_at", Type: "datetime"},
+		{Field: "deleted_at", Type: "datetime", Nullable: true},
+	}
+}
+
+func TestRenderTemplatesRequiresImportPath(t *testing.T) {
+	g := NewGenerator(&Config{
+		Introspector: &fakeIntrospector{columns: testColumns()},
+		OutputPath:   "internal/model",
+		PackageName:  "model",
+		Templates: []Template{
+			{Name: TemplateModel},
+			{Name: TemplateRepository, OutputPath: "internal/repository", Package: "repository"},
+		},
+	})
+	if _, err := g.Render("users"); err == nil || !strings.Contains(err.Error(), "ImportPath") {
+		t.Fatalf("expected missing ImportPath error, got %v", err)
+	}
+
+	// 未开启 BindingTags 时 DTO 不生成 binding 标签
+	g = NewGenerator(&Config{
+		Introspector: &fakeIntrospector{columns: testColumns()},
+		PackageName:  "model",
+		Templates:    []Template{{Name: TemplateDTO}},
+	})
+	files, err := g.Render("users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(files[0].Content, []byte("binding:")) {
+		t.Errorf("unexpected binding tags:\n%s", files[0].Content)
+	}
+}
+
+// TestGeneratedCodeBuilds 将生成的各个包写入模块内的临时目录并编译
+func TestGeneratedCodeBuilds(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping go build in short mode")
+	}
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go command not found")
+	}
+
+	// 以 _ 开头的目录不会被 ./... 匹配，不影响包本身的构建
+	root, err := os.MkdirTemp(".", "_gentest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(root) })
+	base := path.Join("github.com/shrimps80/go-service-utils/genmodel", filepath.Base(root))
+
+	for _, binding := range []bool{false, true} {
+		dir := filepath.Join(root, "binding")
+		if !binding {
+			dir = filepath.Join(root, "plain")
+		}
+		importOf := func(pkg string) string { return path.Join(base, filepath.Base(dir), pkg) }
+
+		g := NewGenerator(&Config{
+			Introspector: &fakeIntrospector{columns: testColumns()},
+			OutputPath:   filepath.Join(dir, "model"),
+			PackageName:  "model",
+			BindingTags:  binding,
+			Templates: []Template{
+				{Name: TemplateModel, ImportPath: importOf("model")},
+				{Name: TemplateRepository, OutputPath: filepath.Join(dir, "repository"), Package: "repository", ImportPath: importOf("repository")},
+				{Name: TemplateDTO, OutputPath: filepath.Join(dir, "dto"), Package: "dto", ImportPath: importOf("dto")},
+				{Name: TemplateConverter, OutputPath: filepath.Join(dir, "dto"), Package: "dto", ImportPath: importOf("dto")},
+			},
+		})
+		if err := g.GenerateModel("users"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	args := []string{"vet"}
+	for _, variant := range []string{"plain", "binding"} {
+		for _, pkg := range []string{"model", "repository", "dto"} {
+			args = append(args, "./"+filepath.ToSlash(filepath.Join(root, variant, pkg)))
+		}
+	}
+	cmd := exec.Command(goBin, args...)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("generated code does not build: %v\n%s", err, out)
+	}
+}
@@ -0,0 +1,213 @@
+package genmodel
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Introspector.GoType 返回的伪类型，由 Generator 按 TypeOptions 替换为具体类型
+const (
+	TypeDecimal = "decimal" // 定点数
+	TypeJSON    = "json"    // JSON 列
+)
+
+// 可空列的映射方式
+const (
+	NullablePointer = "pointer"  // *T（默认）
+	NullableSQLNull = "sql_null" // sql.NullString、sql.NullInt64 等
+	NullableIgnore  = "ignore"   // 忽略可空性，使用值类型
+)
+
+// TypeOptions 类型映射选项
+type TypeOptions struct {
+	Nullable      string         // 可空列的映射方式，默认 NullablePointer
+	Decimal       string         // 定点数对应的 Go 类型，默认 float64，可设为 string 或 decimal.Decimal
+	DecimalImport string         // Decimal 类型所在的包，如 github.com/shopspring/decimal
+	JSON          string         // JSON 列对应的 Go 类型：json.RawMessage（默认）或 datatypes.JSON
+	Overrides     []TypeOverride // 自定义映射，按顺序匹配，命中后不再做可空处理
+}
+
+// TypeOverride 自定义类型映射，Column 与 DBType 同时设置时需同时匹配
+type TypeOverride struct {
+	Column string // 列名通配符，可带表名前缀，如 "*_at"、"orders.amount"
+	DBType string // 数据库类型正则（不区分大小写），如 "^decimal\\(20,"
+	GoType string // 映射到的 Go 类型
+	Import string // GoType 所在的包
+}
+
+// nullTypes 值类型对应的 sql.Null* 类型
+var nullTypes = map[string]string{
+	"string":    "sql.NullString",
+	"bool":      "sql.NullBool",
+	"int8":      "sql.NullInt16",
+	"int16":     "sql.NullInt16",
+	"int32":     "sql.NullInt32",
+	"int":       "sql.NullInt64",
+	"int64":     "sql.NullInt64",
+	"uint8":     "sql.NullByte",
+	"uint16":    "sql.NullInt32",
+	"uint32":    "sql.NullInt64",
+	"float32":   "sql.NullFloat64",
+	"float64":   "sql.NullFloat64",
+	"time.Time": "sql.NullTime",
+}
+
+// typeImports Go 类型所需导入的包
+var typeImports = map[string]string{
+	"time.Time":       "time",
+	"json.RawMessage": "encoding/json",
+	"datatypes.JSON":  "gorm.io/datatypes",
+}
+
+// resolveType 计算列最终的 Go 类型及其需要导入的包
+func (g *Generator) resolveType(in Introspector, table string, col Column) (string, []string) {
+	opts := g.config.Types
+
+	for _, o := range g.overrides {
+		if o.matches(table, col) {
+			if o.Import != "" {
+				return o.GoType, []string{o.Import}
+			}
+			return o.GoType, importsOf(o.GoType)
+		}
+	}
+
+	goType := in.GoType(col)
+	var imports []string
+	switch goType {
+	case TypeDecimal:
+		goType = "float64"
+		if opts.Decimal != "" {
+			goType = opts.Decimal
+		}
+		if opts.DecimalImport != "" {
+			imports = append(imports, opts.DecimalImport)
+		}
+	case TypeJSON:
+		goType = "json.RawMessage"
+		if opts.JSON != "" {
+			goType = opts.JSON
+		}
+	}
+
+	if col.Nullable && !col.IsPrimary && !nilable(goType) {
+		switch opts.Nullable {
+		case NullableIgnore:
+		case NullableSQLNull:
+			if nullType, ok := nullTypes[goType]; ok {
+				return nullType, append(imports, "database/sql")
+			}
+			goType = "*" + goType
+		default:
+			goType = "*" + goType
+		}
+	}
+
+	return goType, append(imports, importsOf(goType)...)
+}
+
+// typeOverride 预编译后的自定义映射
+type typeOverride struct {
+	TypeOverride
+	dbType *regexp.Regexp
+}
+
+// compileOverrides 校验列名通配符并编译类型正则
+func compileOverrides(overrides []TypeOverride) ([]typeOverride, error) {
+	compiled := make([]typeOverride, 0, len(overrides))
+	for i, o := range overrides {
+		if o.Column == "" && o.DBType == "" {
+			return nil, fmt.Errorf("genmodel: type override #%d has neither Column nor DBType", i)
+		}
+		if o.GoType == "" {
+			return nil, fmt.Errorf("genmodel: type override #%d has no GoType", i)
+		}
+		c := typeOverride{TypeOverride: o}
+		if o.Column != "" {
+			if _, err := path.Match(o.Column, ""); err != nil {
+				return nil, fmt.Errorf("genmodel: type override column pattern %q: %w", o.Column, err)
+			}
+		}
+		if o.DBType != "" {
+			re, err := regexp.Compile("(?i)" + o.DBType)
+			if err != nil {
+				return nil, fmt.Errorf("genmodel: type override db type %q: %w", o.DBType, err)
+			}
+			c.dbType = re
+		}
+		compiled = append(compiled, c)
+	}
+	return compiled, nil
+}
+
+// matches 判断自定义映射是否命中列
+func (o typeOverride) matches(table string, col Column) bool {
+	if o.Column != "" {
+		name := col.Field
+		if strings.Contains(o.Column, ".") {
+			name = table + "." + col.Field
+		}
+		if ok, _ := path.Match(o.Column, name); !ok {
+			return false
+		}
+	}
+	if o.dbType != nil && !o.dbType.MatchString(col.Type) {
+		return false
+	}
+	return true
+}
+
+// nilable 判断类型本身是否可以表示 NULL
+func nilable(goType string) bool {
+	return strings.HasPrefix(goType, "*") || strings.HasPrefix(goType, "[]") ||
+		strings.HasPrefix(goType, "map[") || goType == "json.RawMessage" ||
+		goType == "datatypes.JSON" || strings.HasPrefix(goType, "sql.Null")
+}
+
+// importsOf 返回内置映射类型需要导入的包
+func importsOf(goType string) []string {
+	goType = strings.TrimLeft(goType, "*[]")
+	if strings.HasPrefix(goType, "sql.") {
+		return []string{"database/sql"}
+	}
+	if pkg, ok := typeImports[goType]; ok {
+		return []string{pkg}
+	}
+	return nil
+}
+
+// collectImports 汇总所有列需要导入的包，去重并排序
+func collectImports(columns []Column) []string {
+	seen := make(map[string]bool)
+	var imports []string
+	for _, col := range columns {
+		for _, pkg := range col.imports {
+			if !seen[pkg] {
+				seen[pkg] = true
+				imports = append(imports, pkg)
+			}
+		}
+	}
+	sort.Strings(imports)
+	return imports
+}
+
+// parseSQLType 将形如 "bigint(20) unsigned" 的列类型拆分为基础类型、括号内参数与是否无符号
+func parseSQLType(typ string) (base, args string, unsigned bool) {
+	typ = strings.ToLower(strings.TrimSpace(typ))
+	unsigned = strings.Contains(typ, "unsigned")
+
+	base = typ
+	if i := strings.IndexByte(typ, '('); i >= 0 {
+		base = typ[:i]
+		if j := strings.IndexByte(typ[i:], ')'); j >= 0 {
+			args = typ[i+1 : i+j]
+		}
+	} else if i := strings.IndexByte(typ, ' '); i >= 0 {
+		base = typ[:i]
+	}
+	return strings.TrimSpace(base), args, unsigned
+}
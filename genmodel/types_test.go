@@ -0,0 +1,153 @@
+package genmodel
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMySQLGoType(t *testing.T) {
+	in := &mysqlIntrospector{}
+	tests := map[string]string{
+		"tinyint(1)":          "bool",
+		"tinyint(4)":          "int8",
+		"tinyint(3) unsigned": "uint8",
+		"smallint(6)":         "int16",
+		"int(11)":             "int32",
+		"int(10) unsigned":    "uint32",
+		"bigint(20)":          "int64",
+		"bigint unsigned":     "uint64",
+		"decimal(10,2)":       TypeDecimal,
+		"float":               "float32",
+		"double":              "float64",
+		"datetime(3)":         "time.Time",
+		"json":                TypeJSON,
+		"varbinary(16)":       "[]byte",
+		"bit(1)":              "[]byte",
+		"varchar(255)":        "string",
+		"enum('a','b')":       "string",
+	}
+	for typ, want := range tests {
+		if got := in.GoType(Column{Type: typ}); got != want {
+			t.Errorf("GoType(%q) = %q, want %q", typ, got, want)
+		}
+	}
+}
+
+func TestResolveType(t *testing.T) {
+	in := &mysqlIntrospector{}
+	tests := []struct {
+		name        string
+		types       TypeOptions
+		col         Column
+		wantType    string
+		wantImports []string
+	}{
+		{
+			name:     "not null",
+			col:      Column{Field: "age", Type: "int(11)"},
+			wantType: "int32",
+		},
+		{
+			name:        "nullable pointer",
+			col:         Column{Field: "created_at", Type: "datetime", Nullable: true},
+			wantType:    "*time.Time",
+			wantImports: []string{"time"},
+		},
+		{
+			name:        "nullable sql.Null",
+			types:       TypeOptions{Nullable: NullableSQLNull},
+			col:         Column{Field: "name", Type: "varchar(20)", Nullable: true},
+			wantType:    "sql.NullString",
+			wantImports: []string{"database/sql"},
+		},
+		{
+			name:     "nullable bytes stay as is",
+			col:      Column{Field: "data", Type: "blob", Nullable: true},
+			wantType: "[]byte",
+		},
+		{
+			name:        "decimal option",
+			types:       TypeOptions{Decimal: "decimal.Decimal", DecimalImport: "github.com/shopspring/decimal"},
+			col:         Column{Field: "amount", Type: "decimal(10,2)"},
+			wantType:    "decimal.Decimal",
+			wantImports: []string{"github.com/shopspring/decimal"},
+		},
+		{
+			name:        "json default",
+			col:         Column{Field: "meta", Type: "json", Nullable: true},
+			wantType:    "json.RawMessage",
+			wantImports: []string{"encoding/json"},
+		},
+		{
+			name:        "json datatypes",
+			types:       TypeOptions{JSON: "datatypes.JSON"},
+			col:         Column{Field: "meta", Type: "json"},
+			wantType:    "datatypes.JSON",
+			wantImports: []string{"gorm.io/datatypes"},
+		},
+		{
+			name:     "column override with table prefix",
+			types:    TypeOptions{Overrides: []TypeOverride{{Column: "users.status", GoType: "UserStatus"}}},
+			col:      Column{Field: "status", Type: "tinyint(4)", Nullable: true},
+			wantType: "UserStatus",
+		},
+		{
+			name:        "type pattern override",
+			types:       TypeOptions{Overrides: []TypeOverride{{DBType: `^char\(36\)`, GoType: "uuid.UUID", Import: "github.com/google/uuid"}}},
+			col:         Column{Field: "uid", Type: "CHAR(36)"},
+			wantType:    "uuid.UUID",
+			wantImports: []string{"github.com/google/uuid"},
+		},
+		{
+			name:     "override not matching other table",
+			types:    TypeOptions{Overrides: []TypeOverride{{Column: "orders.status", GoType: "OrderStatus"}}},
+			col:      Column{Field: "status", Type: "tinyint(4)"},
+			wantType: "int8",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGenerator(&Config{Types: tt.types, Introspector: in})
+			if _, err := g.getIntrospector(); err != nil {
+				t.Fatal(err)
+			}
+			gotType, gotImports := g.resolveType(in, "users", tt.col)
+			if gotType != tt.wantType {
+				t.Errorf("type = %q, want %q", gotType, tt.wantType)
+			}
+			if !reflect.DeepEqual(gotImports, tt.wantImports) {
+				t.Errorf("imports = %v, want %v", gotImports, tt.wantImports)
+			}
+		})
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	valid := &Config{Types: TypeOptions{Overrides: []TypeOverride{{Column: "*_at", DBType: `^datetime`, GoType: "int64"}}}}
+	if err := valid.Validate(); err != nil {
+		t.Fatalf("Validate() = %v", err)
+	}
+
+	for name, cfg := range map[string]*Config{
+		"bad regexp":    {Types: TypeOptions{Overrides: []TypeOverride{{DBType: `^decimal(`, GoType: "string"}}}},
+		"bad glob":      {Types: TypeOptions{Overrides: []TypeOverride{{Column: "[a-", GoType: "string"}}}},
+		"empty match":   {Types: TypeOptions{Overrides: []TypeOverride{{GoType: "string"}}}},
+		"no go type":    {Types: TypeOptions{Overrides: []TypeOverride{{Column: "id"}}}},
+		"bad include":   {Include: []string{"[users"}},
+		"bad json case": {JSONCase: "kebab"},
+	} {
+		if err := cfg.Validate(); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+
+	// 生成时同样返回配置错误，而不是静默忽略映射
+	g := NewGenerator(&Config{
+		Introspector: &mysqlIntrospector{},
+		Types:        TypeOptions{Overrides: []TypeOverride{{DBType: `(`, GoType: "string"}}},
+	})
+	if _, err := g.Tables(); err == nil {
+		t.Fatal("expected Tables to report the invalid override")
+	}
+}
@@ -0,0 +1,45 @@
+// Package backoff 提供各组件重试时共用的退避计算
+package backoff
+
+import (
+	"math/rand"
+	"time"
+)
+
+// Exponential 计算第 attempt 次（从 0 开始）重试前的等待时间：以 min 为基数指数增长、不超过 max，
+// 并在 [d/2, d] 内随机抖动，避免多个调用方同时重试
+func Exponential(attempt int, min, max time.Duration) time.Duration {
+	d := min << attempt
+	if d <= 0 || (max > 0 && d > max) {
+		d = max
+	}
+	if d <= 0 {
+		return 0
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+// Interval 计算第 attempt 次（从 0 开始）重试前的等待时间：以 initial 为基数按 multiplier 倍增长（小于 1 时按 1）、
+// 不超过 max（为 0 时不限制），并按 jitter 比例（0~1）上下随机抖动
+func Interval(attempt int, initial, max time.Duration, multiplier, jitter float64) time.Duration {
+	if multiplier < 1 {
+		multiplier = 1
+	}
+
+	d := float64(initial)
+	for i := 0; i < attempt; i++ {
+		d *= multiplier
+		if max > 0 && d > float64(max) {
+			d = float64(max)
+			break
+		}
+	}
+
+	if jitter > 0 {
+		d += d * jitter * (rand.Float64()*2 - 1)
+	}
+	if d < 0 {
+		d = 0
+	}
+	return time.Duration(d)
+}
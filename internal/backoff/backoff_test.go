@@ -0,0 +1,43 @@
+package backoff
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExponential(t *testing.T) {
+	min, max := 10*time.Millisecond, 40*time.Millisecond
+	for attempt, want := range []time.Duration{10, 20, 40, 40, 40, 40} {
+		want *= time.Millisecond
+		for i := 0; i < 100; i++ {
+			if d := Exponential(attempt, min, max); d < want/2 || d > want {
+				t.Fatalf("Exponential(%d) = %v, want in [%v, %v]", attempt, d, want/2, want)
+			}
+		}
+	}
+
+	// 移位溢出时取上限
+	if d := Exponential(80, min, max); d < max/2 || d > max {
+		t.Errorf("Exponential(80) = %v, want capped at %v", d, max)
+	}
+	if d := Exponential(3, 0, 0); d != 0 {
+		t.Errorf("Exponential with zero bounds = %v, want 0", d)
+	}
+}
+
+func TestInterval(t *testing.T) {
+	initial, max := 100*time.Millisecond, time.Second
+	for attempt, want := range []time.Duration{100, 200, 400, 800, 1000, 1000} {
+		if got := Interval(attempt, initial, max, 2, 0); got != want*time.Millisecond {
+			t.Errorf("Interval(%d) = %v, want %v", attempt, got, want*time.Millisecond)
+		}
+	}
+	if got := Interval(3, initial, 0, 0.5, 0); got != initial {
+		t.Errorf("Interval with multiplier < 1 = %v, want %v", got, initial)
+	}
+	for i := 0; i < 100; i++ {
+		if got := Interval(1, initial, max, 2, 0.5); got < 100*time.Millisecond || got > 300*time.Millisecond {
+			t.Fatalf("Interval with jitter = %v, want in [100ms, 300ms]", got)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+// Package prom 提供各组件共用的 Prometheus 注册辅助函数
+package prom
+
+import (
+	"errors"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Register 将采集器注册到 reg 并返回实际生效的采集器。
+// 若同名采集器已注册（如组件被初始化多次），返回已存在的采集器而不是panic；其他注册错误仍会panic，与 promauto 行为一致。
+func Register[T prometheus.Collector](reg prometheus.Registerer, c T) T {
+	if reg == nil {
+		reg = prometheus.DefaultRegisterer
+	}
+	if err := reg.Register(c); err != nil {
+		var are prometheus.AlreadyRegisteredError
+		if errors.As(err, &are) {
+			if existing, ok := are.ExistingCollector.(T); ok {
+				return existing
+			}
+		}
+		panic(err)
+	}
+	return c
+}
+
+// Gatherer 返回与 reg 对应的 Gatherer：reg 本身实现了 Gatherer（如 *prometheus.Registry）时直接返回，否则返回默认 Gatherer
+func Gatherer(reg prometheus.Registerer) prometheus.Gatherer {
+	if g, ok := reg.(prometheus.Gatherer); ok {
+		return g
+	}
+	return prometheus.DefaultGatherer
+}
+
+// Replace 将采集器注册到 reg，若同名采集器已注册则先注销旧采集器，用于按实例采集的 GaugeFunc 等需要替换闭包的场景
+func Replace(reg prometheus.Registerer, c prometheus.Collector) {
+	if reg == nil {
+		reg = prometheus.DefaultRegisterer
+	}
+	if err := reg.Register(c); err != nil {
+		var are prometheus.AlreadyRegisteredError
+		if !errors.As(err, &are) {
+			panic(err)
+		}
+		reg.Unregister(are.ExistingCollector)
+		reg.MustRegister(c)
+	}
+}
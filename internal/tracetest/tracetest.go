@@ -0,0 +1,62 @@
+// Package tracetest 提供各组件测试共用的 span 记录器
+package tracetest
+
+import (
+	"context"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// Span 记录名称、父 span、属性与状态的 span
+type Span struct {
+	noop.Span
+	Name   string
+	Parent string
+	Attrs  []attribute.KeyValue
+	Status codes.Code
+	Ended  bool
+}
+
+func (s *Span) SetAttributes(kv ...attribute.KeyValue) { s.Attrs = append(s.Attrs, kv...) }
+func (s *Span) SetStatus(code codes.Code, _ string)    { s.Status = code }
+func (s *Span) End(...trace.SpanEndOption)             { s.Ended = true }
+
+// Provider 记录所有创建的 span
+type Provider struct {
+	noop.TracerProvider
+
+	mu    sync.Mutex
+	spans []*Span
+}
+
+// Tracer 返回将 span 记录到 p 的 Tracer
+func (p *Provider) Tracer(string, ...trace.TracerOption) trace.Tracer {
+	return tracer{p: p}
+}
+
+// Spans 返回已创建的 span
+func (p *Provider) Spans() []*Span {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]*Span(nil), p.spans...)
+}
+
+type tracer struct {
+	noop.Tracer
+	p *Provider
+}
+
+func (t tracer) Start(ctx context.Context, name string, _ ...trace.SpanStartOption) (context.Context, trace.Span) {
+	s := &Span{Name: name}
+	if parent, ok := trace.SpanFromContext(ctx).(*Span); ok {
+		s.Parent = parent.Name
+	}
+	t.p.mu.Lock()
+	t.p.spans = append(t.p.spans, s)
+	t.p.mu.Unlock()
+	return trace.ContextWithSpan(ctx, s), s
+}
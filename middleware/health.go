@@ -1,39 +1,362 @@
 package middleware
 
 import (
+	"context"
 	"net/http"
+	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
-var isHealthy atomic.Bool
+// 健康检查默认参数
+const (
+	DefaultCheckTimeout  = 2 * time.Second // 单项检查超时时间
+	DefaultCheckCacheTTL = time.Second     // 检查结果缓存时间
+)
+
+// 健康状态
+const (
+	StatusHealthy   = "healthy"   // 健康
+	StatusDegraded  = "degraded"  // 非关键检查项失败，仍可提供服务
+	StatusUnhealthy = "unhealthy" // 不健康
+	StatusStarting  = "starting"  // 启动宽限期内检查失败
+)
+
+// defaultHealth 供 Health、SetHealthStatus、RegisterChecker 使用的默认实例
+var defaultHealth = NewHealth(nil)
+
+// HealthOptions 健康检查配置
+type HealthOptions struct {
+	HealthPath         string        // 整体健康状态路径，默认 /health
+	LivenessPath       string        // 存活探针路径，默认 /livez
+	ReadinessPath      string        // 就绪探针路径，默认 /readyz
+	StartupGracePeriod time.Duration // 启动宽限期：期间存活检查失败只标记为 starting，不返回 503
+	CheckTimeout       time.Duration // 检查项默认超时时间，默认 DefaultCheckTimeout
+	CacheTTL           time.Duration // 检查结果默认缓存时间，为 0 时不缓存；NewHealth(nil) 使用 DefaultCheckCacheTTL
+}
+
+// DefaultHealthOptions 返回默认健康检查配置
+func DefaultHealthOptions() *HealthOptions {
+	return &HealthOptions{
+		HealthPath:    "/health",
+		LivenessPath:  "/livez",
+		ReadinessPath: "/readyz",
+		CheckTimeout:  DefaultCheckTimeout,
+		CacheTTL:      DefaultCheckCacheTTL,
+	}
+}
+
+// Checker 健康检查项，返回 nil 表示健康
+type Checker interface {
+	Check(ctx context.Context) error
+}
+
+// CheckerFunc 函数形式的健康检查项
+type CheckerFunc func(ctx context.Context) error
+
+// Check 实现 Checker 接口
+func (f CheckerFunc) Check(ctx context.Context) error {
+	return f(ctx)
+}
+
+// CheckerOption 健康检查项选项
+type CheckerOption func(*checkerEntry)
 
-func init() {
-	isHealthy.Store(true)
+// WithCheckTimeout 设置单项检查超时时间，默认取 HealthOptions.CheckTimeout
+func WithCheckTimeout(timeout time.Duration) CheckerOption {
+	return func(e *checkerEntry) {
+		e.timeout = timeout
+	}
+}
+
+// WithCheckCacheTTL 设置检查结果缓存时间，默认取 HealthOptions.CacheTTL，为 0 时每次请求都重新检查
+func WithCheckCacheTTL(ttl time.Duration) CheckerOption {
+	return func(e *checkerEntry) {
+		e.cacheTTL = ttl
+	}
+}
+
+// WithLiveness 将检查项同时用于存活探针。
+// 存活探针失败会导致容器重启，只应用于进程自身无法恢复的故障，外部依赖（DB、Redis）不应加入。
+func WithLiveness() CheckerOption {
+	return func(e *checkerEntry) {
+		e.liveness = true
+	}
+}
+
+// WithNonCritical 将检查项标记为非关键：失败时整体状态为 degraded，仍返回 200
+func WithNonCritical() CheckerOption {
+	return func(e *checkerEntry) {
+		e.nonCritical = true
+	}
+}
+
+// CheckResult 单项检查结果
+type CheckResult struct {
+	Status   string  `json:"status"`          // healthy / unhealthy
+	Critical bool    `json:"critical"`        // 是否为关键检查项
+	Error    string  `json:"error,omitempty"` // 失败原因
+	Duration float64 `json:"duration_ms"`     // 检查耗时（毫秒）
+	Cached   bool    `json:"cached"`          // 是否来自缓存
+}
+
+// checkerEntry 已注册的健康检查项
+type checkerEntry struct {
+	name        string
+	checker     Checker
+	timeout     time.Duration
+	cacheTTL    time.Duration
+	liveness    bool
+	nonCritical bool
+
+	mu        sync.Mutex
+	last      CheckResult
+	checkedAt time.Time
+	inflight  *checkCall // 正在执行的检查，同一检查项同时最多执行一次
+}
+
+// checkCall 一次正在执行的检查，完成后关闭 done
+type checkCall struct {
+	done chan struct{}
+	err  error
+}
+
+// HealthMonitor 健康检查实例：持有健康状态与检查项，不同实例之间互不影响
+type HealthMonitor struct {
+	opts      HealthOptions
+	startedAt time.Time
+	healthy   atomic.Bool
+
+	mu       sync.RWMutex
+	checkers []*checkerEntry
+}
+
+// NewHealth 创建健康检查实例，opts 中未设置的字段使用默认值
+func NewHealth(opts *HealthOptions) *HealthMonitor {
+	o := *DefaultHealthOptions()
+	if opts != nil {
+		if opts.HealthPath != "" {
+			o.HealthPath = opts.HealthPath
+		}
+		if opts.LivenessPath != "" {
+			o.LivenessPath = opts.LivenessPath
+		}
+		if opts.ReadinessPath != "" {
+			o.ReadinessPath = opts.ReadinessPath
+		}
+		if opts.CheckTimeout > 0 {
+			o.CheckTimeout = opts.CheckTimeout
+		}
+		o.CacheTTL = opts.CacheTTL
+		o.StartupGracePeriod = opts.StartupGracePeriod
+	}
+
+	h := &HealthMonitor{
+		opts:      o,
+		startedAt: time.Now(),
+	}
+	h.healthy.Store(true)
+	return h
+}
+
+// DefaultHealth 返回 Health、SetHealthStatus、RegisterChecker 使用的默认实例
+func DefaultHealth() *HealthMonitor {
+	return defaultHealth
+}
+
+// SetStatus 设置整体健康状态，优雅关闭时置为 false 使就绪探针失败
+func (h *HealthMonitor) SetStatus(healthy bool) {
+	h.healthy.Store(healthy)
+}
+
+// Healthy 返回整体健康状态
+func (h *HealthMonitor) Healthy() bool {
+	return h.healthy.Load()
+}
+
+// RegisterChecker 注册一个命名的健康检查项，所有检查项都参与就绪探针，同名注册会覆盖
+func (h *HealthMonitor) RegisterChecker(name string, checker Checker, opts ...CheckerOption) {
+	entry := &checkerEntry{
+		name:     name,
+		checker:  checker,
+		timeout:  h.opts.CheckTimeout,
+		cacheTTL: h.opts.CacheTTL,
+	}
+	for _, opt := range opts {
+		opt(entry)
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for i, e := range h.checkers {
+		if e.name == name {
+			h.checkers[i] = entry
+			return
+		}
+	}
+	h.checkers = append(h.checkers, entry)
+}
+
+// Handler 返回处理健康检查请求的中间件，非健康检查路径直接放行
+func (h *HealthMonitor) Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.URL.Path {
+		case h.opts.HealthPath:
+			h.writeProbe(c, h.Healthy(), false)
+		case h.opts.LivenessPath:
+			h.writeProbe(c, true, true)
+		case h.opts.ReadinessPath:
+			h.writeProbe(c, h.Healthy(), false)
+		default:
+			c.Next()
+			return
+		}
+		c.Abort()
+	}
 }
 
 // SetHealthStatus sets the health status of the service
 func SetHealthStatus(healthy bool) {
-	isHealthy.Store(healthy)
+	defaultHealth.SetStatus(healthy)
 }
 
-// Health returns a middleware that handles health check requests
+// RegisterChecker 在默认实例上注册健康检查项
+func RegisterChecker(name string, checker Checker, opts ...CheckerOption) {
+	defaultHealth.RegisterChecker(name, checker, opts...)
+}
+
+// Health returns a middleware that handles health check requests using the default instance.
+// /health 返回整体健康状态与所有检查项的结果，/livez 为存活探针，/readyz 为就绪探针（包含整体健康状态与所有检查项）。
 func Health() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		if c.Request.URL.Path == "/health" {
-			if isHealthy.Load() {
-				c.JSON(http.StatusOK, gin.H{
-					"status": "healthy",
-				})
-			} else {
-				c.JSON(http.StatusServiceUnavailable, gin.H{
-					"status": "unhealthy",
-				})
-			}
-			c.Abort()
-			return
+	return defaultHealth.Handler()
+}
+
+// runChecks 并发执行检查项，liveness 为 true 时只执行存活检查项
+func (h *HealthMonitor) runChecks(ctx context.Context, liveness bool) map[string]CheckResult {
+	h.mu.RLock()
+	entries := make([]*checkerEntry, 0, len(h.checkers))
+	for _, e := range h.checkers {
+		if !liveness || e.liveness {
+			entries = append(entries, e)
+		}
+	}
+	h.mu.RUnlock()
+
+	results := make(map[string]CheckResult, len(entries))
+	var (
+		wg sync.WaitGroup
+		mu sync.Mutex
+	)
+	for _, e := range entries {
+		wg.Add(1)
+		go func(e *checkerEntry) {
+			defer wg.Done()
+			res := e.run(ctx)
+			mu.Lock()
+			results[e.name] = res
+			mu.Unlock()
+		}(e)
+	}
+	wg.Wait()
+
+	return results
+}
+
+// run 执行检查或返回缓存的结果。检查在独立的协程中执行，等待期间不持有锁，
+// 并发的请求共享同一次检查；检查项不响应 ctx 而挂起时，该协程会一直存在，
+// 但同一检查项同时最多只有一个，期间的请求在超时后返回失败
+func (e *checkerEntry) run(ctx context.Context) CheckResult {
+	e.mu.Lock()
+	if e.cacheTTL > 0 && !e.checkedAt.IsZero() && time.Since(e.checkedAt) < e.cacheTTL {
+		res := e.last
+		e.mu.Unlock()
+		res.Cached = true
+		return res
+	}
+	call := e.inflight
+	if call == nil {
+		call = &checkCall{done: make(chan struct{})}
+		e.inflight = call
+		go e.check(context.WithoutCancel(ctx), call)
+	}
+	e.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, e.timeout)
+	defer cancel()
+
+	start := time.Now()
+	var err error
+	select {
+	case <-call.done:
+		err = call.err
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	res := CheckResult{
+		Status:   StatusHealthy,
+		Critical: !e.nonCritical,
+		Duration: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		res.Status = StatusUnhealthy
+		res.Error = err.Error()
+	}
+
+	e.mu.Lock()
+	e.last = res
+	e.checkedAt = time.Now()
+	e.mu.Unlock()
+	return res
+}
+
+// check 在超时时间内执行检查项并通知等待方
+func (e *checkerEntry) check(ctx context.Context, call *checkCall) {
+	ctx, cancel := context.WithTimeout(ctx, e.timeout)
+	defer cancel()
+
+	call.err = e.checker.Check(ctx)
+
+	e.mu.Lock()
+	if e.inflight == call {
+		e.inflight = nil
+	}
+	e.mu.Unlock()
+	close(call.done)
+}
+
+// writeProbe 执行检查并输出探针结果：base 为 false 或关键检查项失败时返回 503，
+// 仅非关键检查项失败时返回 200 与 degraded；存活探针在启动宽限期内失败返回 200 与 starting。
+func (h *HealthMonitor) writeProbe(c *gin.Context, base bool, liveness bool) {
+	results := h.runChecks(c.Request.Context(), liveness)
+
+	status := StatusHealthy
+	if !base {
+		status = StatusUnhealthy
+	}
+	for _, res := range results {
+		if res.Status == StatusHealthy {
+			continue
 		}
-		c.Next()
+		if res.Critical {
+			status = StatusUnhealthy
+		} else if status == StatusHealthy {
+			status = StatusDegraded
+		}
+	}
+
+	if status == StatusUnhealthy && liveness && time.Since(h.startedAt) < h.opts.StartupGracePeriod {
+		status = StatusStarting
 	}
-}
\ No newline at end of file
+
+	code := http.StatusOK
+	if status == StatusUnhealthy {
+		code = http.StatusServiceUnavailable
+	}
+	c.JSON(code, gin.H{
+		"status": status,
+		"checks": results,
+	})
+}
@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/shrimps80/go-service-utils/cache"
+	"github.com/shrimps80/go-service-utils/pool"
+)
+
+// SQLChecker 返回通过 Ping 检查数据库连接的检查项，db 可由 gorm.DB 的 DB() 方法获取
+func SQLChecker(db *sql.DB) Checker {
+	return CheckerFunc(func(ctx context.Context) error {
+		return db.PingContext(ctx)
+	})
+}
+
+// RedisChecker 返回通过 PING 命令检查Redis连接的检查项
+func RedisChecker(r *cache.Redis) Checker {
+	return CheckerFunc(r.Ping)
+}
+
+// PoolChecker 返回检查协程池饱和度的检查项：协程池已关闭或等待中的任务数达到 maxWaiting 时视为不健康
+func PoolChecker(p pool.Pool, maxWaiting int) Checker {
+	return CheckerFunc(func(ctx context.Context) error {
+		if p.IsClosed() {
+			return pool.ErrPoolClosed
+		}
+		stats := p.Stats()
+		if stats.WaitingTasks >= maxWaiting {
+			return fmt.Errorf("pool saturated: running=%d/%d waiting=%d", stats.RunningTasks, stats.Size, stats.WaitingTasks)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/shrimps80/go-service-utils/cache"
+	"github.com/shrimps80/go-service-utils/pool"
+)
+
+func TestSQLChecker(t *testing.T) {
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	checker := SQLChecker(db)
+	if err := checker.Check(context.Background()); err != nil {
+		t.Fatalf("Check = %v", err)
+	}
+	_ = db.Close()
+	if err := checker.Check(context.Background()); err == nil {
+		t.Fatal("expected error after Close")
+	}
+}
+
+func TestRedisChecker(t *testing.T) {
+	mr := miniredis.RunT(t)
+	r, err := cache.NewRedis(&cache.RedisConfig{Addrs: []string{mr.Addr()}, PoolSize: 1, Timeout: time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	checker := RedisChecker(r)
+	if err := checker.Check(context.Background()); err != nil {
+		t.Fatalf("Check = %v", err)
+	}
+	mr.Close()
+	if err := checker.Check(context.Background()); err == nil {
+		t.Fatal("expected error after redis stopped")
+	}
+}
+
+func TestPoolChecker(t *testing.T) {
+	p, err := pool.New(1, pool.WithQueueSize(4))
+	if err != nil {
+		t.Fatal(err)
+	}
+	checker := PoolChecker(p, 2)
+	if err := checker.Check(context.Background()); err != nil {
+		t.Fatalf("idle pool: %v", err)
+	}
+
+	// 占住唯一的工作协程，使后续任务排队
+	release := make(chan struct{})
+	started := make(chan struct{})
+	_ = p.Submit(func() error { close(started); <-release; return nil })
+	<-started
+	for i := 0; i < 2; i++ {
+		_ = p.Submit(func() error { return nil })
+	}
+	if err := checker.Check(context.Background()); err == nil {
+		t.Fatal("expected saturated pool to fail")
+	}
+	close(release)
+	p.Wait()
+
+	p.Close()
+	if err := checker.Check(context.Background()); err != pool.ErrPoolClosed {
+		t.Fatalf("closed pool: %v, want ErrPoolClosed", err)
+	}
+}
@@ -0,0 +1,201 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func serveProbe(t *testing.T, h *HealthMonitor, path string) (int, string) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(h.Handler())
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+
+	var body struct {
+		Status string `json:"status"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	return w.Code, body.Status
+}
+
+func failing(ctx context.Context) error {
+	return errors.New("down")
+}
+
+func TestHealth_readinessCriticalFailure(t *testing.T) {
+	h := NewHealth(nil)
+	h.RegisterChecker("db", CheckerFunc(failing))
+
+	if code, status := serveProbe(t, h, "/readyz"); code != http.StatusServiceUnavailable || status != StatusUnhealthy {
+		t.Fatalf("got %d %s", code, status)
+	}
+	// 外部依赖不参与存活探针
+	if code, status := serveProbe(t, h, "/livez"); code != http.StatusOK || status != StatusHealthy {
+		t.Fatalf("got %d %s", code, status)
+	}
+}
+
+func TestHealth_degraded(t *testing.T) {
+	h := NewHealth(nil)
+	h.RegisterChecker("search", CheckerFunc(failing), WithNonCritical())
+
+	if code, status := serveProbe(t, h, "/readyz"); code != http.StatusOK || status != StatusDegraded {
+		t.Fatalf("got %d %s", code, status)
+	}
+}
+
+func TestHealth_startupGracePeriod(t *testing.T) {
+	h := NewHealth(&HealthOptions{StartupGracePeriod: time.Hour})
+	h.RegisterChecker("deadlock", CheckerFunc(failing), WithLiveness())
+
+	if code, status := serveProbe(t, h, "/livez"); code != http.StatusOK || status != StatusStarting {
+		t.Fatalf("got %d %s", code, status)
+	}
+}
+
+func TestHealth_instancesAreIndependent(t *testing.T) {
+	a := NewHealth(&HealthOptions{ReadinessPath: "/ready"})
+	b := NewHealth(nil)
+	a.SetStatus(false)
+
+	if code, _ := serveProbe(t, a, "/ready"); code != http.StatusServiceUnavailable {
+		t.Fatalf("got %d", code)
+	}
+	if code, _ := serveProbe(t, b, "/readyz"); code != http.StatusOK {
+		t.Fatalf("got %d", code)
+	}
+}
+
+func TestHealth_checkTimeoutAndCache(t *testing.T) {
+	h := NewHealth(nil)
+	var calls atomic.Int32
+	h.RegisterChecker("slow", CheckerFunc(func(ctx context.Context) error {
+		calls.Add(1)
+		<-ctx.Done()
+		return ctx.Err()
+	}), WithCheckTimeout(10*time.Millisecond), WithCheckCacheTTL(time.Minute))
+
+	for i := 0; i < 2; i++ {
+		if code, status := serveProbe(t, h, "/readyz"); code != http.StatusServiceUnavailable || status != StatusUnhealthy {
+			t.Fatalf("got %d %s", code, status)
+		}
+	}
+	// 缓存有效期内只执行一次检查
+	time.Sleep(10 * time.Millisecond)
+	if n := calls.Load(); n != 1 {
+		t.Fatalf("checker ran %d times, want 1", n)
+	}
+
+	res := h.runChecks(context.Background(), false)["slow"]
+	if !res.Cached || res.Error != context.DeadlineExceeded.Error() || !res.Critical {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+}
+
+func TestHealth_zeroCacheTTLDisablesCache(t *testing.T) {
+	h := NewHealth(&HealthOptions{CacheTTL: 0})
+	var calls atomic.Int32
+	h.RegisterChecker("db", CheckerFunc(func(context.Context) error {
+		calls.Add(1)
+		return nil
+	}))
+
+	for i := 0; i < 3; i++ {
+		if res := h.runChecks(context.Background(), false)["db"]; res.Cached {
+			t.Fatalf("got cached result with CacheTTL 0: %+v", res)
+		}
+	}
+	if n := calls.Load(); n != 3 {
+		t.Fatalf("checker ran %d times, want 3", n)
+	}
+}
+
+func TestHealth_hungCheckerDoesNotBlockProbes(t *testing.T) {
+	h := NewHealth(&HealthOptions{CacheTTL: 0})
+	release := make(chan struct{})
+	defer close(release)
+	var calls atomic.Int32
+	h.RegisterChecker("hung", CheckerFunc(func(context.Context) error {
+		calls.Add(1)
+		<-release // 不响应 ctx
+		return nil
+	}), WithCheckTimeout(20*time.Millisecond))
+
+	// 并发的探针各自在超时后返回，挂起的检查不会被重复启动
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			start := time.Now()
+			res := h.runChecks(context.Background(), false)["hung"]
+			if res.Error != context.DeadlineExceeded.Error() {
+				t.Errorf("got %+v, want a timeout", res)
+			}
+			if elapsed := time.Since(start); elapsed > time.Second {
+				t.Errorf("probe blocked for %v", elapsed)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := calls.Load(); n != 1 {
+		t.Fatalf("hung checker started %d times, want 1", n)
+	}
+}
+
+func TestHealth_registerCheckerReplaces(t *testing.T) {
+	h := NewHealth(nil)
+	h.RegisterChecker("db", CheckerFunc(failing))
+	h.RegisterChecker("db", CheckerFunc(func(context.Context) error { return nil }))
+
+	if code, status := serveProbe(t, h, "/readyz"); code != http.StatusOK || status != StatusHealthy {
+		t.Fatalf("got %d %s", code, status)
+	}
+	if n := len(h.runChecks(context.Background(), false)); n != 1 {
+		t.Fatalf("got %d checkers, want 1", n)
+	}
+}
+
+func TestHealth_shutdownFailsReadiness(t *testing.T) {
+	h := NewHealth(nil)
+	h.SetStatus(false)
+
+	if code, status := serveProbe(t, h, "/readyz"); code != http.StatusServiceUnavailable || status != StatusUnhealthy {
+		t.Fatalf("got %d %s", code, status)
+	}
+	// 存活探针不受整体状态影响，优雅关闭期间不会触发重启
+	if code, _ := serveProbe(t, h, "/livez"); code != http.StatusOK {
+		t.Fatalf("got %d", code)
+	}
+}
+
+func TestHealth_healthAggregatesCheckers(t *testing.T) {
+	h := NewHealth(nil)
+	if code, status := serveProbe(t, h, "/health"); code != http.StatusOK || status != StatusHealthy {
+		t.Fatalf("got %d %s", code, status)
+	}
+
+	h.RegisterChecker("search", CheckerFunc(failing), WithNonCritical())
+	if code, status := serveProbe(t, h, "/health"); code != http.StatusOK || status != StatusDegraded {
+		t.Fatalf("got %d %s", code, status)
+	}
+
+	h.RegisterChecker("db", CheckerFunc(failing))
+	if code, status := serveProbe(t, h, "/health"); code != http.StatusServiceUnavailable || status != StatusUnhealthy {
+		t.Fatalf("got %d %s", code, status)
+	}
+}
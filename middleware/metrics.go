@@ -1,51 +1,284 @@
 package middleware
 
 import (
+	"net/http"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/prometheus/client_golang/prometheus"
-	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/shrimps80/go-service-utils/internal/prom"
 )
 
-var (
-	httpRequestsTotal = promauto.NewCounterVec(
-		prometheus.CounterOpts{
-			Name: "http_requests_total",
-			Help: "Total number of HTTP requests",
-		},
-		[]string{"method", "path", "status"},
-	)
-
-	httpRequestDuration = promauto.NewHistogramVec(
-		prometheus.HistogramOpts{
-			Name:    "http_request_duration_seconds",
-			Help:    "HTTP request duration in seconds",
-			Buckets: prometheus.DefBuckets,
-		},
-		[]string{"method", "path"},
-	)
-)
+// MetricsOptions 指标采集配置
+type MetricsOptions struct {
+	// Namespace、Subsystem 指标名前缀，如 Namespace=order 时指标名为 order_http_requests_total
+	Namespace string
+	Subsystem string
+
+	// ConstLabels 附加到所有指标上的常量标签，如 service、version
+	ConstLabels prometheus.Labels
+
+	// DurationBuckets 请求延迟直方图的桶，默认 prometheus.DefBuckets
+	DurationBuckets []float64
+
+	// SizeBuckets 请求、响应大小直方图的桶，默认 100B 到 100MB 的指数桶
+	SizeBuckets []float64
+
+	// Registerer 指标注册器，默认 prometheus.DefaultRegisterer；测试或多实例隔离时可传入 prometheus.NewRegistry()
+	Registerer prometheus.Registerer
+
+	// MaxSeries method+path 标签组合的上限，超出后新的组合统一记为 OverflowPath，为 0 时不限制；
+	// 同一注册器上的多个中间件共用采集器，上限按合计计算
+	MaxSeries int
+
+	// UnmatchedPath 未匹配任何路由（404 等）的请求使用的 path 标签，默认 "<unmatched>"
+	UnmatchedPath string
+
+	// OverflowPath 超出 MaxSeries 后使用的 path 标签，默认 "<overflow>"
+	OverflowPath string
+}
+
+// DefaultMetricsOptions 返回默认指标采集配置
+func DefaultMetricsOptions() *MetricsOptions {
+	return &MetricsOptions{
+		DurationBuckets: prometheus.DefBuckets,
+		SizeBuckets:     prometheus.ExponentialBuckets(100, 10, 7),
+		Registerer:      prometheus.DefaultRegisterer,
+		MaxSeries:       1000,
+		UnmatchedPath:   "<unmatched>",
+		OverflowPath:    "<overflow>",
+	}
+}
+
+// httpMetrics HTTP 指标采集器
+type httpMetrics struct {
+	requestsTotal    *prometheus.CounterVec
+	requestDuration  *prometheus.HistogramVec
+	requestSize      *prometheus.HistogramVec
+	responseSize     *prometheus.HistogramVec
+	requestsInFlight prometheus.Gauge
+}
+
+// newHTTPMetrics 创建并注册 HTTP 指标采集器，相同配置重复注册时复用已存在的采集器
+func newHTTPMetrics(opts *MetricsOptions) *httpMetrics {
+	durationBuckets := opts.DurationBuckets
+	if len(durationBuckets) == 0 {
+		durationBuckets = prometheus.DefBuckets
+	}
+	sizeBuckets := opts.SizeBuckets
+	if len(sizeBuckets) == 0 {
+		sizeBuckets = prometheus.ExponentialBuckets(100, 10, 7)
+	}
+	reg := opts.Registerer
+
+	return &httpMetrics{
+		requestsTotal: prom.Register(reg, prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace:   opts.Namespace,
+				Subsystem:   opts.Subsystem,
+				Name:        "http_requests_total",
+				Help:        "Total number of HTTP requests",
+				ConstLabels: opts.ConstLabels,
+			},
+			[]string{"method", "path", "status"},
+		)),
+		requestDuration: prom.Register(reg, prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace:   opts.Namespace,
+				Subsystem:   opts.Subsystem,
+				Name:        "http_request_duration_seconds",
+				Help:        "HTTP request duration in seconds",
+				ConstLabels: opts.ConstLabels,
+				Buckets:     durationBuckets,
+			},
+			[]string{"method", "path"},
+		)),
+		requestSize: prom.Register(reg, prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace:   opts.Namespace,
+				Subsystem:   opts.Subsystem,
+				Name:        "http_request_size_bytes",
+				Help:        "HTTP request size in bytes",
+				ConstLabels: opts.ConstLabels,
+				Buckets:     sizeBuckets,
+			},
+			[]string{"method", "path"},
+		)),
+		responseSize: prom.Register(reg, prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace:   opts.Namespace,
+				Subsystem:   opts.Subsystem,
+				Name:        "http_response_size_bytes",
+				Help:        "HTTP response size in bytes",
+				ConstLabels: opts.ConstLabels,
+				Buckets:     sizeBuckets,
+			},
+			[]string{"method", "path"},
+		)),
+		requestsInFlight: prom.Register(reg, prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace:   opts.Namespace,
+				Subsystem:   opts.Subsystem,
+				Name:        "http_requests_in_flight",
+				Help:        "Number of HTTP requests currently being served",
+				ConstLabels: opts.ConstLabels,
+			},
+		)),
+	}
+}
 
 // Metrics returns a middleware that collects Prometheus metrics for HTTP requests.
 func Metrics() gin.HandlerFunc {
+	return MetricsWithOptions(nil)
+}
+
+// MetricsWithOptions 返回按配置采集 HTTP 指标的中间件。
+// path 标签使用 gin 的路由模板（c.FullPath()，如 /users/:id），避免按实际 URL 产生无限多的时间序列。
+func MetricsWithOptions(opts *MetricsOptions) gin.HandlerFunc {
+	if opts == nil {
+		opts = DefaultMetricsOptions()
+	}
+	m := newHTTPMetrics(opts)
+	guard := newSeriesGuard(opts, m.requestsTotal)
+
 	return func(c *gin.Context) {
 		start := time.Now()
+		m.requestsInFlight.Inc()
+		defer m.requestsInFlight.Dec()
 
 		c.Next()
 
+		method := methodLabel(c.Request.Method)
+		path := guard.pathLabel(method, c.FullPath())
+
 		// 记录请求总数
-		httpRequestsTotal.WithLabelValues(
-			c.Request.Method,
-			c.Request.URL.Path,
+		m.requestsTotal.WithLabelValues(
+			method,
+			path,
 			strconv.Itoa(c.Writer.Status()),
 		).Inc()
 
 		// 记录请求延迟
-		httpRequestDuration.WithLabelValues(
-			c.Request.Method,
-			c.Request.URL.Path,
-		).Observe(time.Since(start).Seconds())
+		m.requestDuration.WithLabelValues(method, path).Observe(time.Since(start).Seconds())
+
+		// 记录请求与响应大小
+		reqSize := c.Request.ContentLength
+		if reqSize < 0 {
+			reqSize = 0
+		}
+		m.requestSize.WithLabelValues(method, path).Observe(float64(reqSize))
+
+		respSize := c.Writer.Size()
+		if respSize < 0 {
+			respSize = 0
+		}
+		m.responseSize.WithLabelValues(method, path).Observe(float64(respSize))
+	}
+}
+
+// MetricsHandler 返回暴露 Prometheus 指标的处理函数，用于挂载 /metrics；g 为空时使用 prometheus.DefaultGatherer
+func MetricsHandler(g prometheus.Gatherer) gin.HandlerFunc {
+	if g == nil {
+		g = prometheus.DefaultGatherer
+	}
+	return gin.WrapH(promhttp.HandlerFor(g, promhttp.HandlerOpts{}))
+}
+
+// knownMethods 作为 method 标签原样保留的 HTTP 方法
+var knownMethods = map[string]struct{}{
+	http.MethodGet:     {},
+	http.MethodHead:    {},
+	http.MethodPost:    {},
+	http.MethodPut:     {},
+	http.MethodPatch:   {},
+	http.MethodDelete:  {},
+	http.MethodConnect: {},
+	http.MethodOptions: {},
+	http.MethodTrace:   {},
+}
+
+// methodLabel 返回请求使用的 method 标签，非标准方法统一记为 OTHER，避免任意方法名产生新的时间序列
+func methodLabel(method string) string {
+	if _, ok := knownMethods[method]; ok {
+		return method
+	}
+	return "OTHER"
+}
+
+// seriesGuard 限制 method+path 标签组合数量，防止时间序列基数膨胀
+type seriesGuard struct {
+	maxSeries     int
+	unmatchedPath string
+	overflowPath  string
+	set           *seriesSet
+}
+
+// seriesSet 已出现的 method+path 标签组合，由共用同一组采集器的中间件共享
+type seriesSet struct {
+	mu   sync.RWMutex
+	seen map[string]struct{}
+}
+
+// seriesSets 按实际生效的 requests_total 采集器记录标签组合：同一注册器上的多个中间件共用采集器（见 prom.Register），
+// 其标签组合合计不超过 MaxSeries
+var (
+	seriesSetsMu sync.Mutex
+	seriesSets   = make(map[*prometheus.CounterVec]*seriesSet)
+)
+
+func newSeriesGuard(opts *MetricsOptions, collector *prometheus.CounterVec) *seriesGuard {
+	seriesSetsMu.Lock()
+	set, ok := seriesSets[collector]
+	if !ok {
+		set = &seriesSet{seen: make(map[string]struct{})}
+		seriesSets[collector] = set
+	}
+	seriesSetsMu.Unlock()
+
+	g := &seriesGuard{
+		maxSeries:     opts.MaxSeries,
+		unmatchedPath: opts.UnmatchedPath,
+		overflowPath:  opts.OverflowPath,
+		set:           set,
+	}
+	if g.unmatchedPath == "" {
+		g.unmatchedPath = "<unmatched>"
+	}
+	if g.overflowPath == "" {
+		g.overflowPath = "<overflow>"
+	}
+	return g
+}
+
+// pathLabel 返回请求使用的 path 标签
+func (g *seriesGuard) pathLabel(method, route string) string {
+	if route == "" {
+		return g.unmatchedPath
+	}
+	if g.maxSeries <= 0 {
+		return route
+	}
+
+	key := method + " " + route
+	set := g.set
+	set.mu.RLock()
+	_, ok := set.seen[key]
+	set.mu.RUnlock()
+	if ok {
+		return route
+	}
+
+	set.mu.Lock()
+	defer set.mu.Unlock()
+	if _, ok := set.seen[key]; ok {
+		return route
+	}
+	if len(set.seen) >= g.maxSeries {
+		return g.overflowPath
 	}
-}
\ No newline at end of file
+	set.seen[key] = struct{}{}
+	return route
+}
@@ -0,0 +1,119 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestMetrics_routeTemplateAndOverflow(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	reg := prometheus.NewRegistry()
+	opts := DefaultMetricsOptions()
+	opts.Namespace = "test"
+	opts.Registerer = reg
+	opts.MaxSeries = 1
+
+	r := gin.New()
+	r.Use(MetricsWithOptions(opts))
+	r.GET("/users/:id", func(c *gin.Context) { c.String(http.StatusOK, "ok") })
+	r.GET("/orders/:id", func(c *gin.Context) { c.String(http.StatusOK, "ok") })
+	r.GET("/metrics", MetricsHandler(reg))
+
+	for _, path := range []string{"/users/1", "/users/2", "/orders/1", "/missing"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body := w.Body.String()
+
+	for _, want := range []string{
+		`test_http_requests_total{method="GET",path="/users/:id",status="200"} 2`,
+		`test_http_requests_total{method="GET",path="<overflow>",status="200"} 1`,
+		`test_http_requests_total{method="GET",path="<unmatched>",status="404"} 1`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("missing %s in\n%s", want, body)
+		}
+	}
+}
+
+func TestMetrics_unknownMethod(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	reg := prometheus.NewRegistry()
+	opts := DefaultMetricsOptions()
+	opts.Registerer = reg
+
+	r := gin.New()
+	r.Use(MetricsWithOptions(opts))
+	r.GET("/metrics", MetricsHandler(reg))
+
+	for _, method := range []string{"FOO", "BAR", http.MethodDelete} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(method, "/missing", nil))
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body := w.Body.String()
+
+	for _, want := range []string{
+		`http_requests_total{method="OTHER",path="<unmatched>",status="404"} 2`,
+		`http_requests_total{method="DELETE",path="<unmatched>",status="404"} 1`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("missing %s in\n%s", want, body)
+		}
+	}
+	if strings.Contains(body, `method="FOO"`) {
+		t.Errorf("raw method leaked into labels:\n%s", body)
+	}
+}
+
+func TestMetrics_registerTwice(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	opts := DefaultMetricsOptions()
+	opts.Registerer = reg
+
+	// 重复创建中间件时复用已注册的采集器，不会panic
+	MetricsWithOptions(opts)
+	MetricsWithOptions(opts)
+}
+
+func TestMetrics_sharedSeriesLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	reg := prometheus.NewRegistry()
+	opts := DefaultMetricsOptions()
+	opts.Namespace = "shared"
+	opts.Registerer = reg
+	opts.MaxSeries = 1
+
+	// 同一注册器上的两个中间件共用采集器，标签组合合计受 MaxSeries 限制
+	first := gin.New()
+	first.Use(MetricsWithOptions(opts))
+	first.GET("/users/:id", func(c *gin.Context) { c.String(http.StatusOK, "ok") })
+	second := gin.New()
+	second.Use(MetricsWithOptions(opts))
+	second.GET("/orders/:id", func(c *gin.Context) { c.String(http.StatusOK, "ok") })
+	second.GET("/metrics", MetricsHandler(reg))
+
+	first.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/1", nil))
+	second.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/orders/1", nil))
+
+	w := httptest.NewRecorder()
+	second.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body := w.Body.String()
+
+	for _, want := range []string{
+		`shared_http_requests_total{method="GET",path="/users/:id",status="200"} 1`,
+		`shared_http_requests_total{method="GET",path="<overflow>",status="200"} 1`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("missing %s in\n%s", want, body)
+		}
+	}
+}
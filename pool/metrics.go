@@ -0,0 +1,148 @@
+package pool
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/shrimps80/go-service-utils/internal/prom"
+)
+
+// poolMetrics 协程池的 Prometheus 指标，按 pool 标签区分不同的协程池
+type poolMetrics struct {
+	queueWait    prometheus.Observer
+	execDuration prometheus.Observer
+	failures     prometheus.Counter
+	panics       prometheus.Counter
+
+	key   statsKey
+	stats []prometheus.Collector // 引用协程池的统计类采集器，关闭时注销
+}
+
+// statsKey 统计类采集器按注册器与协程池名称唯一
+type statsKey struct {
+	reg  prometheus.Registerer
+	name string
+}
+
+// statsOwners 记录每组统计类采集器当前所属的指标实例，
+// 同名协程池重新创建后，旧协程池关闭时不会注销新协程池的采集器
+var (
+	statsMu     sync.Mutex
+	statsOwners = make(map[statsKey]*poolMetrics)
+)
+
+// newPoolMetrics 注册协程池指标：运行、等待、完成、超时等统计取自 Stats，队列等待与执行耗时、失败与 panic 次数在任务执行时记录
+func newPoolMetrics(p *poolImpl, name string, reg prometheus.Registerer) *poolMetrics {
+	labels := prometheus.Labels{"pool": name}
+
+	queueWait := prom.Register(reg, prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "pool_task_queue_wait_seconds",
+			Help:    "Time tasks spend waiting in the pool queue before execution",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"pool"},
+	))
+	execDuration := prom.Register(reg, prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "pool_task_duration_seconds",
+			Help:    "Task execution time in seconds",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"pool"},
+	))
+	failures := prom.Register(reg, prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "pool_task_failures_total",
+			Help: "Total number of tasks that returned an error, timed out or were canceled",
+		},
+		[]string{"pool"},
+	))
+	panics := prom.Register(reg, prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "pool_task_panics_total",
+			Help: "Total number of tasks that panicked",
+		},
+		[]string{"pool"},
+	))
+
+	// 统计类指标按协程池注册，同名协程池重新创建时替换旧的采集器
+	stats := []prometheus.Collector{
+		prometheus.NewGaugeFunc(
+			prometheus.GaugeOpts{Name: "pool_size", Help: "Maximum number of concurrent workers", ConstLabels: labels},
+			func() float64 { return float64(p.size) },
+		),
+		prometheus.NewGaugeFunc(
+			prometheus.GaugeOpts{Name: "pool_running_tasks", Help: "Number of tasks currently running", ConstLabels: labels},
+			func() float64 { return float64(p.Stats().RunningTasks) },
+		),
+		prometheus.NewGaugeFunc(
+			prometheus.GaugeOpts{Name: "pool_waiting_tasks", Help: "Number of tasks waiting in the queue", ConstLabels: labels},
+			func() float64 { return float64(p.Stats().WaitingTasks) },
+		),
+		prometheus.NewCounterFunc(
+			prometheus.CounterOpts{Name: "pool_completed_tasks_total", Help: "Total number of completed tasks", ConstLabels: labels},
+			func() float64 { return float64(p.Stats().CompletedTasks) },
+		),
+		prometheus.NewCounterFunc(
+			prometheus.CounterOpts{Name: "pool_timeout_tasks_total", Help: "Total number of tasks that timed out", ConstLabels: labels},
+			func() float64 { return float64(p.Stats().TimeoutTasks) },
+		),
+	}
+
+	m := &poolMetrics{
+		queueWait:    queueWait.WithLabelValues(name),
+		execDuration: execDuration.WithLabelValues(name),
+		failures:     failures.WithLabelValues(name),
+		panics:       panics.WithLabelValues(name),
+		key:          statsKey{reg: reg, name: name},
+		stats:        stats,
+	}
+
+	statsMu.Lock()
+	defer statsMu.Unlock()
+	for _, c := range stats {
+		prom.Replace(reg, c)
+	}
+	statsOwners[m.key] = m
+	return m
+}
+
+// unregister 注销统计类采集器，使关闭后的协程池不再被注册器引用；采集器已被同名协程池替换时跳过
+func (m *poolMetrics) unregister() {
+	if m == nil {
+		return
+	}
+	statsMu.Lock()
+	defer statsMu.Unlock()
+	if statsOwners[m.key] != m {
+		return
+	}
+	delete(statsOwners, m.key)
+	for _, c := range m.stats {
+		m.key.reg.Unregister(c)
+	}
+}
+
+// observeStart 记录任务排队等待时间
+func (m *poolMetrics) observeStart(added time.Time) {
+	if m == nil {
+		return
+	}
+	m.queueWait.Observe(time.Since(added).Seconds())
+}
+
+// observeDone 记录任务执行耗时与结果
+func (m *poolMetrics) observeDone(start time.Time, err error, panicked bool) {
+	if m == nil {
+		return
+	}
+	m.execDuration.Observe(time.Since(start).Seconds())
+	if err != nil {
+		m.failures.Inc()
+	}
+	if panicked {
+		m.panics.Inc()
+	}
+}
@@ -0,0 +1,99 @@
+package pool
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// gather 返回 pool 标签为 name 的各指标值，直方图取样本数
+func gather(t *testing.T, reg *prometheus.Registry, name string) map[string]float64 {
+	t.Helper()
+	mfs, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	values := make(map[string]float64)
+	for _, mf := range mfs {
+		for _, m := range mf.GetMetric() {
+			for _, lp := range m.GetLabel() {
+				if lp.GetName() != "pool" || lp.GetValue() != name {
+					continue
+				}
+				switch {
+				case m.Histogram != nil:
+					values[mf.GetName()] = float64(m.GetHistogram().GetSampleCount())
+				case m.Counter != nil:
+					values[mf.GetName()] = m.GetCounter().GetValue()
+				case m.Gauge != nil:
+					values[mf.GetName()] = m.GetGauge().GetValue()
+				}
+			}
+		}
+	}
+	return values
+}
+
+func TestPoolMetrics(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	p, err := New(2, WithName("jobs"), WithMetrics(reg), WithPanicHandler(func(interface{}) {}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tasks := []Task{
+		func() error { return nil },
+		func() error { return errors.New("failed") },
+		func() error { panic("boom") },
+	}
+	for _, task := range tasks {
+		if err := p.Submit(task); err != nil {
+			t.Fatal(err)
+		}
+	}
+	p.Wait()
+
+	values := gather(t, reg, "jobs")
+	for name, want := range map[string]float64{
+		"pool_size":                    2,
+		"pool_completed_tasks_total":   3,
+		"pool_task_queue_wait_seconds": 3,
+		"pool_task_duration_seconds":   3,
+		"pool_task_failures_total":     2,
+		"pool_task_panics_total":       1,
+		"pool_running_tasks":           0,
+	} {
+		if got, ok := values[name]; !ok || got != want {
+			t.Errorf("%s = %v (present %v), want %v", name, got, ok, want)
+		}
+	}
+
+	// 关闭后注销统计类采集器，注册器不再引用协程池
+	p.Close()
+	values = gather(t, reg, "jobs")
+	for _, name := range []string{"pool_size", "pool_running_tasks", "pool_waiting_tasks", "pool_completed_tasks_total", "pool_timeout_tasks_total"} {
+		if _, ok := values[name]; ok {
+			t.Errorf("%s still registered after Close", name)
+		}
+	}
+}
+
+func TestPoolMetricsReplacedByNewPool(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	old, err := New(1, WithName("jobs"), WithMetrics(reg))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := New(3, WithName("jobs"), WithMetrics(reg))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+
+	// 旧协程池关闭时不影响同名新协程池的采集器
+	old.Close()
+	if got := gather(t, reg, "jobs")["pool_size"]; got != 3 {
+		t.Fatalf("pool_size = %v, want 3 from the new pool", got)
+	}
+}
@@ -10,6 +10,8 @@ import (
 	"sync"
 	"sync/atomic"
 	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 // Task 表示要在协程池中执行的任务
@@ -104,6 +106,12 @@ type Options struct {
 
 	// PanicHandler 处理任务中的 panic
 	PanicHandler func(interface{})
+
+	// Name 协程池名称，用作指标的 pool 标签
+	Name string
+
+	// MetricsRegisterer 指标注册器，不为空时启用 Prometheus 指标
+	MetricsRegisterer prometheus.Registerer
 }
 
 // Option 协程池选项函数
@@ -130,6 +138,23 @@ func WithPanicHandler(handler func(interface{})) Option {
 	}
 }
 
+// WithName 设置协程池名称，用作指标的 pool 标签
+func WithName(name string) Option {
+	return func(o *Options) {
+		o.Name = name
+	}
+}
+
+// WithMetrics 启用 Prometheus 指标并注册到 reg，reg 为空时使用 prometheus.DefaultRegisterer
+func WithMetrics(reg prometheus.Registerer) Option {
+	return func(o *Options) {
+		if reg == nil {
+			reg = prometheus.DefaultRegisterer
+		}
+		o.MetricsRegisterer = reg
+	}
+}
+
 // TaskOptions 任务选项
 type TaskOptions struct {
 	// Priority 任务优先级，默认为 PriorityNormal
@@ -169,6 +194,7 @@ type poolImpl struct {
 	timeoutTasks   int64            // 超时的任务数
 	dispatcherWg   sync.WaitGroup   // 用于等待调度器协程完成
 	options        Options          // 协程池选项
+	metrics        *poolMetrics     // 指标，未启用时为 nil
 }
 
 // taskWrapper 任务包装器
@@ -219,6 +245,15 @@ func New(size int, opts ...Option) (Pool, error) {
 		options: options,
 	}
 
+	// 启用指标
+	if options.MetricsRegisterer != nil {
+		name := options.Name
+		if name == "" {
+			name = "default"
+		}
+		p.metrics = newPoolMetrics(p, name, options.MetricsRegisterer)
+	}
+
 	// 根据是否启用优先级功能，初始化不同的任务队列
 	if options.EnablePriority {
 		pq := make(priorityQueue, 0)
@@ -437,6 +472,9 @@ func (p *poolImpl) processTask(tw taskWrapper) {
 	atomic.AddInt32(&p.runningTasks, 1)
 	defer atomic.AddInt32(&p.runningTasks, -1)
 
+	p.metrics.observeStart(tw.added)
+	start := time.Now()
+
 	// 创建带超时的上下文（如果需要）
 	var (
 		ctx    context.Context
@@ -454,10 +492,12 @@ func (p *poolImpl) processTask(tw taskWrapper) {
 	done := make(chan struct{})
 	var result interface{}
 	var err error
+	var panicked bool
 
 	go func() {
 		defer func() {
 			if r := recover(); r != nil {
+				panicked = true
 				err = fmt.Errorf("任务panic: %v", r)
 				if p.options.PanicHandler != nil {
 					p.options.PanicHandler(r)
@@ -479,6 +519,7 @@ func (p *poolImpl) processTask(tw taskWrapper) {
 	select {
 	case <-done:
 		// 任务正常完成
+		p.metrics.observeDone(start, err, panicked)
 	case <-ctx.Done():
 		if ctx.Err() == context.DeadlineExceeded {
 			// 任务超时
@@ -488,6 +529,7 @@ func (p *poolImpl) processTask(tw taskWrapper) {
 			// 上下文取消
 			err = ErrContextCanceled
 		}
+		p.metrics.observeDone(start, err, false)
 	}
 
 	// 设置Future结果（如果有）
@@ -544,7 +586,7 @@ func (p *poolImpl) Wait() {
 	p.wg.Wait()
 }
 
-// Close 关闭协程池，不再接受新任务
+// Close 关闭协程池，不再接受新任务，并注销引用协程池的统计指标
 func (p *poolImpl) Close() {
 	// 原子操作设置关闭标志
 	if atomic.CompareAndSwapInt32(&p.closed, 0, 1) {
@@ -555,6 +597,7 @@ func (p *poolImpl) Close() {
 			// 关闭任务队列
 			close(p.taskQueue)
 		}
+		p.metrics.unregister()
 	}
 }
 
@@ -0,0 +1,112 @@
+package utils
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+// ErrInvalidCursor 游标格式错误或签名校验失败
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// CursorPage 游标分页数据：nextCursor / prevCursor 为不透明的游标，为空表示该方向没有更多数据。
+type CursorPage struct {
+	List       any    `json:"list"`
+	PageSize   int    `json:"pageSize"`
+	NextCursor string `json:"nextCursor"`
+	PrevCursor string `json:"prevCursor"`
+	HasMore    bool   `json:"hasMore"`
+}
+
+// NewCursorPage 构造游标分页数据，通常作为 Success / SuccessCursorPage 的 data 载荷。
+func NewCursorPage(list any, pageSize int, nextCursor, prevCursor string, hasMore bool) *CursorPage {
+	return &CursorPage{
+		List:       list,
+		PageSize:   pageSize,
+		NextCursor: nextCursor,
+		PrevCursor: prevCursor,
+		HasMore:    hasMore,
+	}
+}
+
+// Cursor 游标内容：排序键的取值与翻页方向。
+type Cursor struct {
+	Values   []any `json:"v"`           // 排序键取值，与排序键一一对应
+	Backward bool  `json:"b,omitempty"` // 是否向前翻页（上一页）
+}
+
+// CursorCodec 游标编解码器：游标为 base64url 编码的 JSON 加 HMAC-SHA256 签名，客户端无法篡改。
+type CursorCodec struct {
+	secret []byte
+}
+
+// NewCursorCodec 创建游标编解码器，secret 用于签名，多实例部署时需保持一致。
+// secret 为空时签名可被任意伪造，视为配置错误并 panic。
+func NewCursorCodec(secret []byte) *CursorCodec {
+	if len(secret) == 0 {
+		panic("utils: cursor codec secret must not be empty")
+	}
+	return &CursorCodec{secret: secret}
+}
+
+// Encode 编码并签名游标。
+func (c *CursorCodec) Encode(cur Cursor) (string, error) {
+	payload, err := json.Marshal(cur)
+	if err != nil {
+		return "", err
+	}
+
+	enc := base64.RawURLEncoding
+	return enc.EncodeToString(payload) + "." + enc.EncodeToString(c.sign(payload)), nil
+}
+
+// Decode 校验签名并解码游标；数字取值还原为 int64（整数）或 float64，时间等其他类型为字符串。
+func (c *CursorCodec) Decode(s string) (*Cursor, error) {
+	enc := base64.RawURLEncoding
+
+	encPayload, encSig, ok := strings.Cut(s, ".")
+	if !ok {
+		return nil, ErrInvalidCursor
+	}
+	payload, err := enc.DecodeString(encPayload)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	sig, err := enc.DecodeString(encSig)
+	if err != nil || !hmac.Equal(sig, c.sign(payload)) {
+		return nil, ErrInvalidCursor
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(payload))
+	dec.UseNumber()
+
+	var cur Cursor
+	if err := dec.Decode(&cur); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	for i, v := range cur.Values {
+		n, ok := v.(json.Number)
+		if !ok {
+			continue
+		}
+		if iv, err := n.Int64(); err == nil {
+			cur.Values[i] = iv
+		} else if fv, err := n.Float64(); err == nil {
+			cur.Values[i] = fv
+		} else {
+			return nil, ErrInvalidCursor
+		}
+	}
+	return &cur, nil
+}
+
+// sign 计算签名
+func (c *CursorCodec) sign(payload []byte) []byte {
+	mac := hmac.New(sha256.New, c.secret)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
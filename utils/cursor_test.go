@@ -0,0 +1,59 @@
+package utils
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCursorCodec_RoundTrip(t *testing.T) {
+	codec := NewCursorCodec([]byte("secret"))
+	s, err := codec.Encode(Cursor{Values: []any{int64(42), 1.5, "2024-01-02T03:04:05Z"}, Backward: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cur, err := codec.Decode(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cur.Backward || len(cur.Values) != 3 {
+		t.Fatalf("unexpected cursor: %+v", cur)
+	}
+	if v, ok := cur.Values[0].(int64); !ok || v != 42 {
+		t.Errorf("values[0] = %#v, want int64(42)", cur.Values[0])
+	}
+	if v, ok := cur.Values[1].(float64); !ok || v != 1.5 {
+		t.Errorf("values[1] = %#v, want 1.5", cur.Values[1])
+	}
+	if v, ok := cur.Values[2].(string); !ok || v != "2024-01-02T03:04:05Z" {
+		t.Errorf("values[2] = %#v", cur.Values[2])
+	}
+}
+
+func TestCursorCodec_Tampered(t *testing.T) {
+	codec := NewCursorCodec([]byte("secret"))
+	s, err := codec.Encode(Cursor{Values: []any{1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	other, _ := NewCursorCodec([]byte("other")).Encode(Cursor{Values: []any{2}})
+	for _, bad := range []string{"", "abc", s + "x", other[:len(other)-3] + s[len(s)-3:], other} {
+		if _, err := codec.Decode(bad); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("Decode(%q) err = %v, want ErrInvalidCursor", bad, err)
+		}
+	}
+}
+
+func TestNewCursorCodecEmptySecret(t *testing.T) {
+	for _, secret := range [][]byte{nil, {}} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("NewCursorCodec(%q) did not panic", secret)
+				}
+			}()
+			NewCursorCodec(secret)
+		}()
+	}
+}
@@ -57,6 +57,11 @@ func SuccessPage(c *gin.Context, list interface{}, pageNum, pageSize int, total
 	Success(c, NewPageData(list, pageNum, pageSize, total), opts...)
 }
 
+// SuccessCursorPage 成功游标分页响应：将 list、pageSize 与前后游标封装为 CursorPage 后写入标准 Success 信封。
+func SuccessCursorPage(c *gin.Context, list interface{}, pageSize int, nextCursor, prevCursor string, hasMore bool, opts ...ResponseOption) {
+	Success(c, NewCursorPage(list, pageSize, nextCursor, prevCursor, hasMore), opts...)
+}
+
 // Error 错误响应
 func Error(c *gin.Context, errCode *ErrorCode, opts ...ResponseOption) {
 	resp := &Response{